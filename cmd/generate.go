@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"h-ui/dao"
+	"h-ui/model/dto"
+	"h-ui/service"
+	"h-ui/util"
+	"os"
+	"time"
+)
+
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate accounts in bulk",
+	Long:  "Generate accounts in bulk and write their credentials to a CSV file.",
+	Run:   runGenerate,
+}
+
+var (
+	generatePattern    string
+	generateCount      int64
+	generateStart      int64
+	generatePlanId     int64
+	generateQuota      int64
+	generateExpireDays int64
+	generateDeviceNo   int64
+	generateProtocol   string
+	generateHost       string
+	generateOutput     string
+)
+
+func init() {
+	generateCmd.Flags().StringVar(&generatePattern, "pattern", "", "Username pattern, {n} is replaced by the sequence number, without it the pattern is a prefix")
+	generateCmd.Flags().Int64Var(&generateCount, "count", 0, "Number of accounts")
+	generateCmd.Flags().Int64Var(&generateStart, "start", 1, "First sequence number")
+	generateCmd.Flags().Int64Var(&generatePlanId, "plan-id", 0, "Plan of the accounts")
+	generateCmd.Flags().Int64Var(&generateQuota, "quota", -1, "Quota in bytes, -1 is unlimited, ignored with --plan-id")
+	generateCmd.Flags().Int64Var(&generateExpireDays, "expire-days", 30, "Days until the accounts expire, ignored with --plan-id")
+	generateCmd.Flags().Int64Var(&generateDeviceNo, "device-no", 3, "Limit the number of devices, ignored with --plan-id")
+	generateCmd.Flags().StringVar(&generateProtocol, "protocol", "https:", "Protocol of the subscription url")
+	generateCmd.Flags().StringVar(&generateHost, "host", "", "Host of the subscription url and hysteria2 link, e.g. example.com:8081")
+	generateCmd.Flags().StringVarP(&generateOutput, "output", "o", "", "CSV file path")
+	_ = generateCmd.MarkFlagRequired("pattern")
+	_ = generateCmd.MarkFlagRequired("count")
+	_ = generateCmd.MarkFlagRequired("host")
+	_ = generateCmd.MarkFlagRequired("output")
+	rootCmd.AddCommand(generateCmd)
+}
+
+func runGenerate(cmd *cobra.Command, args []string) {
+	if generateCount < 1 || generateCount > 1000 {
+		fmt.Println("the count range is between 1-1000")
+		os.Exit(1)
+	}
+	accountGenerateDto := dto.AccountGenerateDto{
+		Pattern:  &generatePattern,
+		Count:    &generateCount,
+		Start:    &generateStart,
+		Protocol: &generateProtocol,
+		Host:     &generateHost,
+	}
+	if generatePlanId > 0 {
+		accountGenerateDto.PlanId = &generatePlanId
+	} else {
+		expireTime := time.Now().AddDate(0, 0, int(generateExpireDays)).UnixMilli()
+		accountGenerateDto.Quota = &generateQuota
+		accountGenerateDto.ExpireTime = &expireTime
+		accountGenerateDto.DeviceNo = &generateDeviceNo
+	}
+
+	if err := dao.InitSql(""); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	accountGenerates, err := service.GenerateAccount(accountGenerateDto)
+	if closeErr := dao.CloseSqliteDB(); closeErr != nil {
+		fmt.Println(closeErr.Error())
+	}
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	if err = util.ExportCsv(generateOutput, service.AccountGenerateCsv(accountGenerates)); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	fmt.Println(fmt.Sprintf("h-ui generated %d accounts: %s", len(accountGenerates), generateOutput))
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"h-ui/util"
+	"os"
+)
+
+var hysteria2Cmd = &cobra.Command{
+	Use:   "hysteria2",
+	Short: "Manage the installed hysteria2 versions",
+	Long:  "Manage the installed hysteria2 versions without downloading from GitHub.",
+}
+
+var hysteria2InstallCmd = &cobra.Command{
+	Use:   "install <path>",
+	Short: "Install a hysteria2 binary from a local file",
+	Long:  "Install a hysteria2 binary from a local file, the version is detected by running it.",
+	Args:  cobra.ExactArgs(1),
+	Run:   runHysteria2Install,
+}
+
+var hysteria2ListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the installed hysteria2 versions",
+	Long:  "List the installed hysteria2 versions.",
+	Run:   runHysteria2List,
+}
+
+var hysteria2UseCmd = &cobra.Command{
+	Use:   "use <version>",
+	Short: "Switch to an installed hysteria2 version",
+	Long:  "Switch to an installed hysteria2 version, it takes effect when hysteria2 is restarted.",
+	Args:  cobra.ExactArgs(1),
+	Run:   runHysteria2Use,
+}
+
+var hysteria2RollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Switch back to the previous hysteria2 version",
+	Long:  "Switch back to the hysteria2 version that was active before the last switch, it takes effect when hysteria2 is restarted.",
+	Run:   runHysteria2Rollback,
+}
+
+var hysteria2InstallUse bool
+
+func init() {
+	hysteria2InstallCmd.Flags().BoolVarP(&hysteria2InstallUse, "use", "u", false, "Switch to the installed version")
+	hysteria2Cmd.AddCommand(hysteria2InstallCmd, hysteria2ListCmd, hysteria2UseCmd, hysteria2RollbackCmd)
+	rootCmd.AddCommand(hysteria2Cmd)
+}
+
+func runHysteria2Install(cmd *cobra.Command, args []string) {
+	file, err := os.Open(args[0])
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	defer file.Close()
+	version, err := util.InstallHysteria2(file)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	fmt.Println("hysteria2 installed", version)
+	if hysteria2InstallUse {
+		runHysteria2Use(cmd, []string{version})
+	}
+}
+
+func runHysteria2List(cmd *cobra.Command, args []string) {
+	versions, err := util.ListHysteria2Version()
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	var activeVersion string
+	if util.Exists(util.GetHysteria2BinPath()) {
+		activeVersion, _ = util.GetHysteria2BinVersion(util.GetHysteria2BinPath())
+	}
+	previousVersion := util.GetPreviousHysteria2Version()
+	for _, item := range versions {
+		if item == activeVersion {
+			fmt.Println("*", item)
+		} else if item == previousVersion {
+			fmt.Println("-", item, "(previous)")
+		} else {
+			fmt.Println(" ", item)
+		}
+	}
+}
+
+func runHysteria2Use(cmd *cobra.Command, args []string) {
+	if err := util.UseHysteria2Version(args[0]); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	fmt.Println("hysteria2 switched to", args[0], ", restart hysteria2 to take effect")
+}
+
+func runHysteria2Rollback(cmd *cobra.Command, args []string) {
+	version, err := util.RollbackHysteria2Version()
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	fmt.Println("hysteria2 rolled back to", version, ", restart hysteria2 to take effect")
+}
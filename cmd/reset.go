@@ -30,13 +30,18 @@ func runReset(cmd *cobra.Command, args []string) {
 		fmt.Println(err.Error())
 		os.Exit(1)
 	}
+	passEncrypt, err := util.HashPassword(password)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
 	if err = dao.InitSqliteDB(); err != nil {
 		fmt.Println(err.Error())
 		os.Exit(1)
 	}
 	if err = dao.UpdateAccount([]int64{1}, map[string]interface{}{
 		"username": username,
-		"pass":     util.SHA224String(password),
+		"pass":     passEncrypt,
 		"con_pass": fmt.Sprintf("%s.%s", username, password)}); err != nil {
 		fmt.Println(err.Error())
 		os.Exit(1)
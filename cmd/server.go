@@ -35,6 +35,9 @@ func runServer(port string) error {
 	if err := middleware.InitCron(); err != nil {
 		return err
 	}
+	if err := service.InitReleaseSource(); err != nil {
+		logrus.Errorf(err.Error())
+	}
 	if err := service.InitHysteria2(); err != nil {
 		return err
 	}
@@ -82,7 +85,7 @@ func releaseResource() {
 }
 
 func initFile() error {
-	var dirs = []string{constant.LogDir, constant.SqliteDBDir, constant.BinDir, constant.ExportPathDir}
+	var dirs = []string{constant.LogDir, constant.SqliteDBDir, constant.BinDir, constant.Hysteria2AclDir, constant.ExportPathDir}
 	for _, item := range dirs {
 		if !util.Exists(item) {
 			if err := os.Mkdir(item, os.ModePerm); err != nil {
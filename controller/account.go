@@ -1,7 +1,6 @@
 package controller
 
 import (
-	"encoding/json"
 	"fmt"
 	"h-ui/model/constant"
 	"h-ui/model/dto"
@@ -10,6 +9,7 @@ import (
 	"h-ui/service"
 	"h-ui/util"
 	"io"
+	"strconv"
 	"strings"
 	"time"
 
@@ -28,7 +28,7 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	token, err := service.Login(*loginDto.Username, util.SHA224String(*loginDto.Pass))
+	token, err := service.Login(*loginDto.Username, *loginDto.Pass)
 	if err != nil {
 		vo.Fail(err.Error(), c)
 		return
@@ -58,25 +58,16 @@ func PageAccount(c *gin.Context) {
 		return
 	}
 
+	accountTags, err := listAccountTags(accounts)
+	if err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+
 	var accountVos []vo.AccountVo
 	for _, item := range accounts {
-		accountVo := vo.AccountVo{
-			Username:     *item.Username,
-			Quota:        *item.Quota,
-			Download:     *item.Download,
-			Upload:       *item.Upload,
-			ExpireTime:   *item.ExpireTime,
-			KickUtilTime: *item.KickUtilTime,
-			DeviceNo:     *item.DeviceNo,
-			Role:         *item.Role,
-			Deleted:      *item.Deleted,
-			BaseVo: vo.BaseVo{
-				Id:         *item.Id,
-				CreateTime: *item.CreateTime,
-			},
-			LoginAt: *item.LoginAt,
-			ConAt:   *item.ConAt,
-		}
+		accountVo := newAccountVo(item)
+		accountVo.Tags = accountTags[*item.Id]
 		if value, exists := onlineUsers[*item.Username]; exists {
 			accountVo.Online = true
 			accountVo.Device = value
@@ -91,6 +82,74 @@ func PageAccount(c *gin.Context) {
 	vo.Success(accountPageVo, c)
 }
 
+func PageTrashAccount(c *gin.Context) {
+	accountPageDto, err := validateField(c, dto.AccountPageDto{})
+	if err != nil {
+		return
+	}
+	accounts, total, err := service.PageTrashAccount(accountPageDto)
+	if err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+
+	accountTags, err := listAccountTags(accounts)
+	if err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+
+	var accountVos []vo.AccountVo
+	for _, item := range accounts {
+		accountVo := newAccountVo(item)
+		accountVo.Tags = accountTags[*item.Id]
+		accountVos = append(accountVos, accountVo)
+	}
+	accountPageVo := vo.AccountPageVo{
+		AccountVos: accountVos,
+		Total:      total,
+	}
+	vo.Success(accountPageVo, c)
+}
+
+func listAccountTags(accounts []entity.Account) (map[int64][]string, error) {
+	var ids []int64
+	for _, item := range accounts {
+		ids = append(ids, *item.Id)
+	}
+	return service.ListAccountTags(ids)
+}
+
+func newAccountVo(item entity.Account) vo.AccountVo {
+	return vo.AccountVo{
+		Username:     *item.Username,
+		Quota:        *item.Quota,
+		Download:     *item.Download,
+		Upload:       *item.Upload,
+		ExpireTime:   *item.ExpireTime,
+		KickUtilTime: *item.KickUtilTime,
+		DeviceNo:     *item.DeviceNo,
+		Role:         *item.Role,
+		Deleted:      *item.Deleted,
+		BaseVo: vo.BaseVo{
+			Id:         *item.Id,
+			CreateTime: *item.CreateTime,
+		},
+		LoginAt: *item.LoginAt,
+		ConAt:   *item.ConAt,
+		PlanId:  *item.PlanId,
+
+		ResetCycle:    *item.ResetCycle,
+		ResetInterval: *item.ResetInterval,
+		ResetAt:       *item.ResetAt,
+
+		TrashedAt: *item.TrashedAt,
+
+		TrafficMultiplier: *item.TrafficMultiplier,
+		BillingMode:       *item.BillingMode,
+	}
+}
+
 func SaveAccount(c *gin.Context) {
 	accountSaveDto, err := validateField(c, dto.AccountSaveDto{})
 	if err != nil {
@@ -102,7 +161,33 @@ func SaveAccount(c *gin.Context) {
 		return
 	}
 
-	passEncrypt := util.SHA224String(*accountSaveDto.Pass)
+	if accountSaveDto.PlanId != nil {
+		plan, err := service.GetPlan(*accountSaveDto.PlanId)
+		if err != nil {
+			vo.Fail(err.Error(), c)
+			return
+		}
+		if accountSaveDto.Quota == nil {
+			accountSaveDto.Quota = plan.Quota
+		}
+		if accountSaveDto.ExpireTime == nil {
+			expireTime := time.Now().AddDate(0, 0, int(*plan.Duration)).UnixMilli()
+			accountSaveDto.ExpireTime = &expireTime
+		}
+		if accountSaveDto.DeviceNo == nil {
+			accountSaveDto.DeviceNo = plan.DeviceNo
+		}
+		if accountSaveDto.ResetCycle == nil {
+			accountSaveDto.ResetCycle = plan.ResetCycle
+			accountSaveDto.ResetInterval = plan.ResetInterval
+		}
+	}
+
+	passEncrypt, err := util.HashPassword(*accountSaveDto.Pass)
+	if err != nil {
+		vo.Fail(constant.SysError, c)
+		return
+	}
 	conPass := fmt.Sprintf("%s.%s", *accountSaveDto.Username, *accountSaveDto.ConPass)
 	account := entity.Account{
 		Username:   accountSaveDto.Username,
@@ -112,6 +197,13 @@ func SaveAccount(c *gin.Context) {
 		ExpireTime: accountSaveDto.ExpireTime,
 		DeviceNo:   accountSaveDto.DeviceNo,
 		Deleted:    accountSaveDto.Deleted,
+		PlanId:     accountSaveDto.PlanId,
+
+		ResetCycle:    accountSaveDto.ResetCycle,
+		ResetInterval: accountSaveDto.ResetInterval,
+
+		TrafficMultiplier: accountSaveDto.TrafficMultiplier,
+		BillingMode:       accountSaveDto.BillingMode,
 	}
 	err = service.SaveAccount(account)
 	if err != nil {
@@ -143,6 +235,30 @@ func DeleteAccount(c *gin.Context) {
 	vo.Success(nil, c)
 }
 
+func RestoreAccount(c *gin.Context) {
+	idsDto, err := validateField(c, dto.IdsDto{})
+	if err != nil {
+		return
+	}
+	if err = service.RestoreAccount(idsDto.Ids); err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	vo.Success(nil, c)
+}
+
+func PurgeAccount(c *gin.Context) {
+	idsDto, err := validateField(c, dto.IdsDto{})
+	if err != nil {
+		return
+	}
+	if err = service.PurgeAccount(idsDto.Ids); err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	vo.Success(nil, c)
+}
+
 func UpdateAccount(c *gin.Context) {
 	accountUpdateDto, err := validateField(c, dto.AccountUpdateDto{})
 	if err != nil {
@@ -168,8 +284,12 @@ func UpdateAccount(c *gin.Context) {
 
 	var passEncrypt *string
 	if accountUpdateDto.Pass != nil && *accountUpdateDto.Pass != "" {
-		passEncryptSha224 := util.SHA224String(*accountUpdateDto.Pass)
-		passEncrypt = &passEncryptSha224
+		passEncryptBcrypt, err := util.HashPassword(*accountUpdateDto.Pass)
+		if err != nil {
+			vo.Fail(constant.SysError, c)
+			return
+		}
+		passEncrypt = &passEncryptBcrypt
 	}
 
 	account := entity.Account{
@@ -180,6 +300,12 @@ func UpdateAccount(c *gin.Context) {
 		ExpireTime: accountUpdateDto.ExpireTime,
 		DeviceNo:   accountUpdateDto.DeviceNo,
 		Deleted:    accountUpdateDto.Deleted,
+
+		ResetCycle:    accountUpdateDto.ResetCycle,
+		ResetInterval: accountUpdateDto.ResetInterval,
+
+		TrafficMultiplier: accountUpdateDto.TrafficMultiplier,
+		BillingMode:       accountUpdateDto.BillingMode,
 		BaseEntity: entity.BaseEntity{
 			Id: accountUpdateDto.Id,
 		},
@@ -244,6 +370,14 @@ func GetAccount(c *gin.Context) {
 		DeviceNo:   *account.DeviceNo,
 		Role:       *account.Role,
 		Deleted:    *account.Deleted,
+		PlanId:     *account.PlanId,
+
+		ResetCycle:    *account.ResetCycle,
+		ResetInterval: *account.ResetInterval,
+		ResetAt:       *account.ResetAt,
+
+		TrafficMultiplier: *account.TrafficMultiplier,
+		BillingMode:       *account.BillingMode,
 	}
 	vo.Success(accountVo, c)
 }
@@ -259,26 +393,24 @@ func ImportAccount(c *gin.Context) {
 		vo.Fail("the file is too big", c)
 		return
 	}
-	// 文件后缀.json
-	if !strings.HasSuffix(header.Filename, ".json") {
+	// 文件后缀.json .csv
+	if !strings.HasSuffix(header.Filename, ".json") && !strings.HasSuffix(header.Filename, ".csv") {
 		vo.Fail("file format not supported", c)
 		return
 	}
 	content, err := io.ReadAll(file)
 	if err != nil {
-		vo.Fail("json file read err", c)
+		vo.Fail("file read err", c)
 		return
 	}
-	var accounts []entity.Account
-	if err = json.Unmarshal(content, &accounts); err != nil {
-		vo.Fail("content Unmarshal err", c)
-		return
-	}
-	if err = service.UpsertAccount(accounts); err != nil {
+	// dryRun only previews the rows, nothing is committed
+	dryRun, _ := strconv.ParseBool(c.PostForm("dryRun"))
+	accountImportVo, err := service.ImportAccount(header.Filename, content, dryRun, validateImportRow)
+	if err != nil {
 		vo.Fail(err.Error(), c)
 		return
 	}
-	vo.Success(nil, c)
+	vo.Success(accountImportVo, c)
 }
 
 func ExportAccount(c *gin.Context) {
@@ -307,6 +439,25 @@ func ExportAccount(c *gin.Context) {
 	c.File(filePath)
 }
 
+func ExportAccountCsv(c *gin.Context) {
+	records, err := service.AccountExportCsv()
+	if err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	writeCsv(c, fmt.Sprintf("AccountExport-%s.csv", time.Now().Format("20060102150405")), records)
+}
+
+// writeCsv stream the csv as a download, the account csvs hold the passwords so they are never kept in the export directory
+func writeCsv(c *gin.Context, fileName string, records [][]string) {
+	c.Header("Content-Type", "application/octet-stream")
+	c.Header("Content-Transfer-Encoding", "binary")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fileName))
+	if err := util.WriteCsv(c.Writer, records); err != nil {
+		_ = c.Error(err)
+	}
+}
+
 func ReleaseKickAccount(c *gin.Context) {
 	idDto, err := validateField(c, dto.IdDto{})
 	if err != nil {
@@ -330,5 +481,92 @@ func VerifyDefaultPass(c *gin.Context) {
 		vo.Fail(err.Error(), c)
 		return
 	}
-	vo.Success(account.Pass != nil && *account.Pass == "02f382b76ca1ab7aa06ab03345c7712fd5b971fb0c0f2aef98bac9cd", c)
+	vo.Success(service.IsDefaultPass(account), c)
+}
+
+func ListAccountTraffic(c *gin.Context) {
+	accountTrafficDto, err := validateField(c, dto.AccountTrafficDto{})
+	if err != nil {
+		return
+	}
+	accountTraffics, err := service.ListAccountTraffic(accountTrafficDto)
+	if err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	accountTrafficVos := make([]vo.AccountTrafficVo, 0, len(accountTraffics))
+	for _, item := range accountTraffics {
+		accountTrafficVos = append(accountTrafficVos, vo.AccountTrafficVo{
+			Time:     *item.PeriodTime,
+			Download: *item.Download,
+			Upload:   *item.Upload,
+		})
+	}
+	vo.Success(accountTrafficVos, c)
+}
+
+func AssignPlan(c *gin.Context) {
+	planAssignDto, err := validateField(c, dto.PlanAssignDto{})
+	if err != nil {
+		return
+	}
+	if err = service.AssignPlan(planAssignDto.Ids, *planAssignDto.PlanId); err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	vo.Success(nil, c)
+}
+
+func RenewAccount(c *gin.Context) {
+	accountRenewDto, err := validateField(c, dto.AccountRenewDto{})
+	if err != nil {
+		return
+	}
+	if err = service.RenewAccount(accountRenewDto.Ids); err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	vo.Success(nil, c)
+}
+
+func GenerateAccount(c *gin.Context) {
+	accountGenerateDto, err := validateField(c, dto.AccountGenerateDto{})
+	if err != nil {
+		return
+	}
+	accountGenerates, err := service.GenerateAccount(accountGenerateDto)
+	if err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+
+	writeCsv(c, fmt.Sprintf("AccountGenerate-%s.csv", time.Now().Format("20060102150405")), service.AccountGenerateCsv(accountGenerates))
+}
+
+func RankAccount(c *gin.Context) {
+	accountRankDto, err := validateField(c, dto.AccountRankDto{})
+	if err != nil {
+		return
+	}
+	report, err := service.RankAccount(accountRankDto)
+	if err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	vo.Success(report, c)
+}
+
+func ExportAccountRankCsv(c *gin.Context) {
+	accountRankDto, err := validateField(c, dto.AccountRankDto{})
+	if err != nil {
+		return
+	}
+	report, err := service.RankAccount(accountRankDto)
+	if err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+
+	fileName := fmt.Sprintf("AccountRank-%s.csv", time.Now().Format("20060102150405"))
+	writeCsv(c, fileName, service.AccountRankCsv(report))
 }
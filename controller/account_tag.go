@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"h-ui/model/dto"
+	"h-ui/model/vo"
+	"h-ui/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func ListAccountTag(c *gin.Context) {
+	accountTagCounts, err := service.ListAccountTagCount()
+	if err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	accountTagVos := []vo.AccountTagVo{}
+	for _, item := range accountTagCounts {
+		accountTagVos = append(accountTagVos, vo.AccountTagVo{
+			Tag:   item.Tag,
+			Count: item.Count,
+		})
+	}
+	vo.Success(accountTagVos, c)
+}
+
+func SaveAccountTag(c *gin.Context) {
+	accountTagDto, err := validateField(c, dto.AccountTagDto{})
+	if err != nil {
+		return
+	}
+	if err = service.SaveAccountTag(accountTagDto.Ids, accountTagDto.Tags); err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	vo.Success(nil, c)
+}
+
+func DeleteAccountTag(c *gin.Context) {
+	accountTagDto, err := validateField(c, dto.AccountTagDto{})
+	if err != nil {
+		return
+	}
+	if err = service.DeleteAccountTag(accountTagDto.Ids, accountTagDto.Tags); err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	vo.Success(nil, c)
+}
+
+func AccountTagAction(c *gin.Context) {
+	accountTagActionDto, err := validateField(c, dto.AccountTagActionDto{})
+	if err != nil {
+		return
+	}
+	if err = service.AccountTagAction(accountTagActionDto); err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	vo.Success(nil, c)
+}
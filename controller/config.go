@@ -15,6 +15,7 @@ import (
 	"h-ui/service"
 	"h-ui/util"
 	"io"
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -37,6 +38,7 @@ func UpdateConfigs(c *gin.Context) {
 
 	needResetPortHopping := false
 	needRestart := false
+	needResetReleaseSource := false
 
 	for _, item := range configsUpdateDto.ConfigUpdateDtos {
 		key := *item.Key
@@ -92,6 +94,10 @@ func UpdateConfigs(c *gin.Context) {
 				return
 			}
 			if *hysteria2ConfigPortHopping.Value != value {
+				fieldErrors, err := service.ValidateHysteria2PortHopping(0, value)
+				if !verifyFieldErrors(c, fieldErrors, err) {
+					return
+				}
 				needResetPortHopping = true
 			}
 		}
@@ -107,6 +113,80 @@ func UpdateConfigs(c *gin.Context) {
 			}
 		}
 
+		if key == constant.AccountTrafficRetention {
+			retention, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || retention < 0 {
+				vo.Fail(fmt.Sprintf("account traffic retention: %s is invalid", value), c)
+				return
+			}
+		}
+
+		if key == constant.TelegramAlertQuotaThreshold || key == constant.TelegramAlertExpireThreshold {
+			if _, err := util.ParseThresholds(value); err != nil {
+				vo.Fail(err.Error(), c)
+				return
+			}
+		}
+
+		if key == constant.Hysteria2StopTimeout {
+			stopTimeout, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || stopTimeout < 0 {
+				vo.Fail(fmt.Sprintf("hysteria2 stop timeout: %s is invalid", value), c)
+				return
+			}
+		}
+
+		if key == constant.Hysteria2StartTimeout {
+			startTimeout, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || startTimeout <= 0 {
+				vo.Fail(fmt.Sprintf("hysteria2 start timeout: %s is invalid", value), c)
+				return
+			}
+		}
+
+		if key == constant.ReleaseCacheTtl {
+			cacheTtl, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || cacheTtl < 0 {
+				vo.Fail(fmt.Sprintf("release cache ttl: %s is invalid", value), c)
+				return
+			}
+		}
+
+		if (key == constant.ReleaseGithubUrl || key == constant.ReleaseMirrorUrl) && value != "" {
+			releaseUrl, err := url.Parse(value)
+			if err != nil || (releaseUrl.Scheme != "http" && releaseUrl.Scheme != "https") || releaseUrl.Host == "" {
+				vo.Fail(fmt.Sprintf("release url: %s is invalid", value), c)
+				return
+			}
+			if key == constant.ReleaseMirrorUrl && !strings.Contains(value, "{url}") && !strings.Contains(value, "{name}") {
+				vo.Fail("release mirror url must contain {url} or {name}", c)
+				return
+			}
+		}
+
+		if key == constant.ReleaseGithubToken || key == constant.ReleaseGithubUrl ||
+			key == constant.ReleaseMirrorUrl || key == constant.ReleaseCacheTtl {
+			needResetReleaseSource = true
+		}
+
+		if key == constant.MetricsToken && value != "" && len(value) < 16 {
+			vo.Fail("metrics token must be at least 16 characters", c)
+			return
+		}
+
+		if key == constant.MetricsAccountEnable && value != "0" && value != "1" {
+			vo.Fail(fmt.Sprintf("metrics account enable: %s is invalid", value), c)
+			return
+		}
+
+		if key == constant.AccountTrashRetention {
+			retention, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || retention < 0 {
+				vo.Fail(fmt.Sprintf("account trash retention: %s is invalid", value), c)
+				return
+			}
+		}
+
 		if key == constant.TelegramEnable {
 			telegramEnable, err := service.GetConfig(constant.TelegramEnable)
 			if err != nil {
@@ -131,6 +211,13 @@ func UpdateConfigs(c *gin.Context) {
 		}
 	}
 
+	if needResetReleaseSource {
+		if err := service.InitReleaseSource(); err != nil {
+			vo.Fail(err.Error(), c)
+			return
+		}
+	}
+
 	if needRestart {
 		go func() {
 			_ = service.StopServer()
@@ -202,11 +289,41 @@ func GetHysteria2Config(c *gin.Context) {
 	vo.Success(config, c)
 }
 
+// ValidateHysteria2Config dry run of UpdateHysteria2Config, every error is returned and nothing is applied
+func ValidateHysteria2Config(c *gin.Context) {
+	var hysteria2ServerConfig bo.Hysteria2ServerConfig
+	if err := c.ShouldBindJSON(&hysteria2ServerConfig); err != nil {
+		vo.Fail(fmt.Sprintf("Invalid request format: %v", err), c)
+		return
+	}
+	hysteria2ConfigValidateVo := vo.Hysteria2ConfigValidateVo{Errors: []vo.FieldErrorVo{}}
+	if err := validate.Struct(&hysteria2ServerConfig); err != nil {
+		hysteria2ConfigValidateVo.Errors = append(hysteria2ConfigValidateVo.Errors, validationFieldErrors(hysteria2ServerConfig, err)...)
+	}
+	fieldErrors, err := service.ValidateHysteria2Config(hysteria2ServerConfig)
+	if err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	hysteria2ConfigValidateVo.Errors = append(hysteria2ConfigValidateVo.Errors, fieldErrors...)
+	hysteria2ConfigValidateVo.Valid = len(hysteria2ConfigValidateVo.Errors) == 0
+	vo.Success(hysteria2ConfigValidateVo, c)
+}
+
+// verifyHysteria2Config fail the request with the semantic errors of the config
+func verifyHysteria2Config(c *gin.Context, hysteria2ServerConfig bo.Hysteria2ServerConfig) bool {
+	fieldErrors, err := service.ValidateHysteria2Config(hysteria2ServerConfig)
+	return verifyFieldErrors(c, fieldErrors, err)
+}
+
 func UpdateHysteria2Config(c *gin.Context) {
 	hysteria2ServerConfig, err := validateField(c, bo.Hysteria2ServerConfig{})
 	if err != nil {
 		return
 	}
+	if !verifyHysteria2Config(c, hysteria2ServerConfig) {
+		return
+	}
 
 	hysteria2Config, err := service.GetHysteria2Config()
 	if err != nil {
@@ -223,7 +340,7 @@ func UpdateHysteria2Config(c *gin.Context) {
 		needResetPortHopping = true
 	}
 
-	if err = service.UpdateHysteria2Config(hysteria2ServerConfig); err != nil {
+	if err = service.UpdateHysteria2Config(hysteria2ServerConfig, service.GetUsername(c)); err != nil {
 		vo.Fail(err.Error(), c)
 		return
 	}
@@ -235,13 +352,6 @@ func UpdateHysteria2Config(c *gin.Context) {
 		}
 	}
 
-	running := service.Hysteria2IsRunning()
-	if running {
-		if err = service.RestartHysteria2(); err != nil {
-			vo.Fail(err.Error(), c)
-			return
-		}
-	}
 	vo.Success(nil, c)
 }
 
@@ -334,6 +444,9 @@ func ImportHysteria2Config(c *gin.Context) {
 		vo.Fail("content Unmarshal err", c)
 		return
 	}
+	if !verifyHysteria2Config(c, hysteria2ServerConfig) {
+		return
+	}
 
 	// 默认值
 	config, err := dao.ListConfig("key in ?", []string{constant.HUIWebPort, constant.JwtSecret})
@@ -375,19 +488,11 @@ func ImportHysteria2Config(c *gin.Context) {
 	hysteria2ServerConfig.Auth = &auth
 	hysteria2ServerConfig.TrafficStats.Secret = &jwtSecret
 
-	if err = service.SetHysteria2Config(hysteria2ServerConfig); err != nil {
+	if err = service.SetHysteria2Config(hysteria2ServerConfig, service.GetUsername(c)); err != nil {
 		vo.Fail(err.Error(), c)
 		return
 	}
 
-	running := service.Hysteria2IsRunning()
-	if running {
-		if err = service.RestartHysteria2(); err != nil {
-			vo.Fail(err.Error(), c)
-			return
-		}
-	}
-
 	vo.Success(nil, c)
 }
 
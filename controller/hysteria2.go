@@ -11,7 +11,6 @@ import (
 	"h-ui/model/vo"
 	"h-ui/service"
 	"h-ui/util"
-	"os"
 	"strings"
 	"time"
 )
@@ -58,27 +57,110 @@ func Hysteria2ChangeVersion(c *gin.Context) {
 		return
 	}
 
-	running := service.Hysteria2IsRunning()
+	running := service.Hysteria2AnyRunning()
 	if running {
-		vo.Fail("please stop hysteria2 first", c)
+		vo.Fail("please stop hysteria2 and the inbounds first", c)
 		return
 	}
 
-	if util.Exists(util.GetHysteria2BinPath()) {
-		if err := os.Remove(util.GetHysteria2BinPath()); err != nil {
-			vo.Fail("filed remove hysteria2 bin file", c)
-			return
-		}
+	// an installed version is switched to without downloading
+	versions, err := util.ListHysteria2Version()
+	if err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	if util.ArrContain(versions, *hysteria2VersionDto.Version) {
+		err = util.UseHysteria2Version(*hysteria2VersionDto.Version)
+	} else {
+		err = util.DownloadHysteria2(fmt.Sprintf("app/%s", *hysteria2VersionDto.Version))
+	}
+	if err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+
+	vo.Success(nil, c)
+}
+
+// UploadHysteria2 install an uploaded hysteria2 binary, for servers that cannot reach github
+func UploadHysteria2(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		vo.Fail(constant.SysError, c)
+		return
+	}
+	if file.Size > 128*1024*1024 {
+		vo.Fail("the file is too big", c)
+		return
+	}
+	reader, err := file.Open()
+	if err != nil {
+		vo.Fail(constant.SysError, c)
+		return
+	}
+	defer reader.Close()
+
+	version, err := util.InstallHysteria2(reader)
+	if err != nil {
+		vo.Fail(err.Error(), c)
+		return
 	}
+	vo.Success(version, c)
+}
 
-	if err = util.DownloadHysteria2(fmt.Sprintf("app/%s", *hysteria2VersionDto.Version)); err != nil {
+func ListHysteria2Version(c *gin.Context) {
+	versions, err := util.ListHysteria2Version()
+	if err != nil {
 		vo.Fail(err.Error(), c)
 		return
 	}
+	var activeVersion string
+	if util.Exists(util.GetHysteria2BinPath()) {
+		activeVersion, _ = util.GetHysteria2BinVersion(util.GetHysteria2BinPath())
+	}
+	previousVersion := util.GetPreviousHysteria2Version()
+	hysteria2VersionVos := make([]vo.Hysteria2VersionVo, 0, len(versions))
+	for _, item := range versions {
+		hysteria2VersionVos = append(hysteria2VersionVos, vo.Hysteria2VersionVo{
+			Version:  item,
+			Active:   item == activeVersion,
+			Previous: item == previousVersion,
+		})
+	}
+	vo.Success(hysteria2VersionVos, c)
+}
 
+func UseHysteria2Version(c *gin.Context) {
+	hysteria2VersionDto, err := validateField(c, dto.Hysteria2VersionDto{})
+	if err != nil {
+		return
+	}
+	if service.Hysteria2AnyRunning() {
+		vo.Fail("please stop hysteria2 and the inbounds first", c)
+		return
+	}
+	if err = util.UseHysteria2Version(*hysteria2VersionDto.Version); err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
 	vo.Success(nil, c)
 }
 
+// RollbackHysteria2Version switch back to the version that was active before the last switch, like any version switch
+// hysteria2 must be stopped first
+func RollbackHysteria2Version(c *gin.Context) {
+	if service.Hysteria2AnyRunning() {
+		vo.Fail("please stop hysteria2 and the inbounds first", c)
+		return
+	}
+	version, err := util.RollbackHysteria2Version()
+	if err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	vo.Success(version, c)
+}
+
 func ListRelease(c *gin.Context) {
 	releases, err := util.ListRelease("apernet", "hysteria")
 	if err != nil {
@@ -88,18 +170,15 @@ func ListRelease(c *gin.Context) {
 
 	var versions []string
 	for _, item := range releases {
-		versionSplit := strings.Split(*item.TagName, "/v")
+		versionSplit := strings.Split(item.TagName, "/v")
 		if len(versionSplit) == 2 {
 			// >= v2.4.4
 			result := util.CompareVersion(versionSplit[1], "2.4.4")
 			if result < 0 {
 				break
 			}
-			for _, asset := range item.Assets {
-				if asset.GetName() == util.GetHysteria2BinName() {
-					versions = append(versions, fmt.Sprintf("v%s", versionSplit[1]))
-					break
-				}
+			if _, ok := item.Assets[util.GetHysteria2BinName()]; ok {
+				versions = append(versions, fmt.Sprintf("v%s", versionSplit[1]))
 			}
 		}
 	}
@@ -112,7 +191,11 @@ func Hysteria2Url(c *gin.Context) {
 		return
 	}
 
-	url, err := service.Hysteria2Url(*hysteria2UrlDto.AccountId, *hysteria2UrlDto.Hostname)
+	var inboundId int64
+	if hysteria2UrlDto.InboundId != nil {
+		inboundId = *hysteria2UrlDto.InboundId
+	}
+	url, err := service.Hysteria2Url(*hysteria2UrlDto.AccountId, *hysteria2UrlDto.Hostname, inboundId)
 	if err != nil {
 		vo.Fail(err.Error(), c)
 		return
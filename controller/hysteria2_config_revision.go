@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"h-ui/model/dto"
+	"h-ui/model/entity"
+	"h-ui/model/vo"
+	"h-ui/service"
+)
+
+func PageHysteria2ConfigRevision(c *gin.Context) {
+	baseDto, err := validateField(c, dto.BaseDto{})
+	if err != nil {
+		return
+	}
+	revisions, total, err := service.PageHysteria2ConfigRevision(baseDto)
+	if err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	revisionVos := make([]vo.Hysteria2ConfigRevisionVo, 0, len(revisions))
+	for _, item := range revisions {
+		revisionVos = append(revisionVos, toHysteria2ConfigRevisionVo(item))
+	}
+	vo.Success(vo.Hysteria2ConfigRevisionPageVo{
+		Hysteria2ConfigRevisionVos: revisionVos,
+		Total:                      total,
+	}, c)
+}
+
+func GetHysteria2ConfigRevision(c *gin.Context) {
+	idDto, err := validateField(c, dto.IdDto{})
+	if err != nil {
+		return
+	}
+	revision, err := service.GetHysteria2ConfigRevision(*idDto.Id)
+	if err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	vo.Success(toHysteria2ConfigRevisionVo(revision), c)
+}
+
+func DiffHysteria2ConfigRevision(c *gin.Context) {
+	revisionDiffDto, err := validateField(c, dto.Hysteria2ConfigRevisionDiffDto{})
+	if err != nil {
+		return
+	}
+	diff, err := service.DiffHysteria2ConfigRevision(*revisionDiffDto.FromId, *revisionDiffDto.ToId)
+	if err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	vo.Success(diff, c)
+}
+
+func RollbackHysteria2Config(c *gin.Context) {
+	idDto, err := validateField(c, dto.IdDto{})
+	if err != nil {
+		return
+	}
+	if err = service.RollbackHysteria2Config(*idDto.Id, service.GetUsername(c)); err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	vo.Success(nil, c)
+}
+
+func toHysteria2ConfigRevisionVo(revision entity.Hysteria2ConfigRevision) vo.Hysteria2ConfigRevisionVo {
+	revisionVo := vo.Hysteria2ConfigRevisionVo{
+		BaseVo: vo.BaseVo{
+			Id:         *revision.Id,
+			CreateTime: *revision.CreateTime,
+		},
+		Operator: *revision.Operator,
+		Remark:   *revision.Remark,
+	}
+	// the page does not select the config and the diff
+	if revision.Config != nil {
+		revisionVo.Config = *revision.Config
+	}
+	if revision.Diff != nil {
+		revisionVo.Diff = *revision.Diff
+	}
+	return revisionVo
+}
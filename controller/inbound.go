@@ -0,0 +1,194 @@
+package controller
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"h-ui/model/bo"
+	"h-ui/model/dto"
+	"h-ui/model/entity"
+	"h-ui/model/vo"
+	"h-ui/service"
+	"regexp"
+	"strings"
+)
+
+func PageInbound(c *gin.Context) {
+	inboundPageDto, err := validateField(c, dto.InboundPageDto{})
+	if err != nil {
+		return
+	}
+	inbounds, total, err := service.PageInbound(inboundPageDto)
+	if err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	inboundVos := make([]vo.InboundVo, 0, len(inbounds))
+	for _, item := range inbounds {
+		inboundVos = append(inboundVos, toInboundVo(item))
+	}
+	vo.Success(vo.InboundPageVo{
+		InboundVos: inboundVos,
+		Total:      total,
+	}, c)
+}
+
+func ListInbound(c *gin.Context) {
+	inbounds, err := service.ListInbound()
+	if err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	inboundVos := make([]vo.InboundVo, 0, len(inbounds))
+	for _, item := range inbounds {
+		inboundVos = append(inboundVos, toInboundVo(item))
+	}
+	vo.Success(inboundVos, c)
+}
+
+func GetInbound(c *gin.Context) {
+	idDto, err := validateField(c, dto.IdDto{})
+	if err != nil {
+		return
+	}
+	inbound, err := service.GetInbound(*idDto.Id)
+	if err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	vo.Success(toInboundVo(inbound), c)
+}
+
+func SaveInbound(c *gin.Context) {
+	inboundSaveDto, err := validateField(c, dto.InboundSaveDto{})
+	if err != nil {
+		return
+	}
+	if service.ExistInboundName(*inboundSaveDto.Name, 0) {
+		vo.Fail(fmt.Sprintf("inbound %s already exists", *inboundSaveDto.Name), c)
+		return
+	}
+	portHopping := ""
+	if inboundSaveDto.PortHopping != nil {
+		portHopping = *inboundSaveDto.PortHopping
+	}
+	if !verifyPortHopping(c, portHopping) {
+		return
+	}
+	if !verifyInboundConfig(c, 0, *inboundSaveDto.Config, portHopping) {
+		return
+	}
+	var enable int64
+	if inboundSaveDto.Enable != nil {
+		enable = *inboundSaveDto.Enable
+	}
+	if err = service.SaveInbound(*inboundSaveDto.Name, *inboundSaveDto.Config, portHopping, enable); err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	vo.Success(nil, c)
+}
+
+func UpdateInbound(c *gin.Context) {
+	inboundUpdateDto, err := validateField(c, dto.InboundUpdateDto{})
+	if err != nil {
+		return
+	}
+	if inboundUpdateDto.Name != nil && *inboundUpdateDto.Name != "" && service.ExistInboundName(*inboundUpdateDto.Name, *inboundUpdateDto.Id) {
+		vo.Fail(fmt.Sprintf("inbound %s already exists", *inboundUpdateDto.Name), c)
+		return
+	}
+	if inboundUpdateDto.PortHopping != nil && !verifyPortHopping(c, *inboundUpdateDto.PortHopping) {
+		return
+	}
+	if inboundUpdateDto.Config != nil {
+		// the ports of the new config are checked against the port hopping it will run with
+		var portHopping string
+		if inboundUpdateDto.PortHopping != nil {
+			portHopping = *inboundUpdateDto.PortHopping
+		} else {
+			inbound, err := service.GetInbound(*inboundUpdateDto.Id)
+			if err != nil {
+				vo.Fail(err.Error(), c)
+				return
+			}
+			portHopping = *inbound.PortHopping
+		}
+		if !verifyInboundConfig(c, *inboundUpdateDto.Id, *inboundUpdateDto.Config, portHopping) {
+			return
+		}
+	} else if inboundUpdateDto.PortHopping != nil {
+		fieldErrors, err := service.ValidateHysteria2PortHopping(*inboundUpdateDto.Id, *inboundUpdateDto.PortHopping)
+		if !verifyFieldErrors(c, fieldErrors, err) {
+			return
+		}
+	}
+	if err = service.UpdateInbound(*inboundUpdateDto.Id,
+		inboundUpdateDto.Name,
+		inboundUpdateDto.Config,
+		inboundUpdateDto.PortHopping,
+		inboundUpdateDto.Enable); err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	vo.Success(nil, c)
+}
+
+func DeleteInbound(c *gin.Context) {
+	idDto, err := validateField(c, dto.IdDto{})
+	if err != nil {
+		return
+	}
+	if err = service.DeleteInbound([]int64{*idDto.Id}); err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	vo.Success(nil, c)
+}
+
+// verifyInboundConfig fail the request with the semantic errors of the config
+func verifyInboundConfig(c *gin.Context, id int64, hysteria2ServerConfig bo.Hysteria2ServerConfig, portHopping string) bool {
+	fieldErrors, err := service.ValidateInboundConfig(id, hysteria2ServerConfig, portHopping)
+	return verifyFieldErrors(c, fieldErrors, err)
+}
+
+// verifyFieldErrors fail the request with the field errors of a validation
+func verifyFieldErrors(c *gin.Context, fieldErrors []vo.FieldErrorVo, err error) bool {
+	if err != nil {
+		vo.Fail(err.Error(), c)
+		return false
+	}
+	if len(fieldErrors) > 0 {
+		var messages []string
+		for _, item := range fieldErrors {
+			messages = append(messages, fmt.Sprintf("%s: %s", item.Field, item.Message))
+		}
+		vo.Fail(strings.Join(messages, "; "), c)
+		return false
+	}
+	return true
+}
+
+func verifyPortHopping(c *gin.Context, portHopping string) bool {
+	re := regexp.MustCompile("^\\d+(?:-\\d+)?(?:,\\d+(?:-\\d+)?)*$")
+	if portHopping != "" && !re.MatchString(portHopping) {
+		vo.Fail(fmt.Sprintf("port hopping: %s is invalid", portHopping), c)
+		return false
+	}
+	return true
+}
+
+func toInboundVo(inbound entity.Inbound) vo.InboundVo {
+	// a config that cannot be parsed is shown empty so it can be fixed
+	config, _ := service.GetInboundConfig(inbound)
+	return vo.InboundVo{
+		BaseVo: vo.BaseVo{
+			Id:         *inbound.Id,
+			CreateTime: *inbound.CreateTime,
+		},
+		Name:        *inbound.Name,
+		Config:      config,
+		PortHopping: *inbound.PortHopping,
+		Enable:      *inbound.Enable,
+		Running:     service.InboundIsRunning(*inbound.Id),
+	}
+}
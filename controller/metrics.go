@@ -0,0 +1,23 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"h-ui/service"
+	"h-ui/util"
+	"net/http"
+)
+
+// Metrics the prometheus text format, scraped with the METRICS_TOKEN as bearer token
+func Metrics(c *gin.Context) {
+	metrics, err := service.ListMetrics()
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err = util.WritePrometheus(c.Writer, metrics); err != nil {
+		logrus.Errorf("write metrics err: %v", err)
+	}
+}
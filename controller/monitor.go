@@ -2,6 +2,7 @@ package controller
 
 import (
 	"github.com/gin-gonic/gin"
+	"h-ui/model/dto"
 	"h-ui/model/vo"
 	"h-ui/service"
 )
@@ -24,3 +25,57 @@ func MonitorHysteria2(c *gin.Context) {
 	vo.Success(hysteria2MonitorVo, c)
 	return
 }
+
+// ListHysteria2Metrics the resource usage history of hysteria2 for the charts
+func ListHysteria2Metrics(c *gin.Context) {
+	hysteria2MetricsDto, err := validateField(c, dto.Hysteria2MetricsDto{})
+	if err != nil {
+		return
+	}
+	var inboundId int64
+	if hysteria2MetricsDto.InboundId != nil {
+		inboundId = *hysteria2MetricsDto.InboundId
+	}
+	history := service.ListHysteria2Metrics(inboundId)
+	hysteria2MetricsVos := make([]vo.Hysteria2MetricsVo, 0, len(history))
+	for _, item := range history {
+		hysteria2MetricsVos = append(hysteria2MetricsVos, vo.Hysteria2MetricsVo{
+			Time:       item.Time,
+			CpuPercent: item.CpuPercent,
+			Rss:        item.Rss,
+			Fds:        item.Fds,
+			Threads:    item.Threads,
+			Uptime:     item.Uptime,
+			UdpDrops:   item.UdpDrops,
+		})
+	}
+	vo.Success(hysteria2MetricsVos, c)
+}
+
+// ListSystemMetrics the system usage history of the window for the dashboards
+func ListSystemMetrics(c *gin.Context) {
+	systemMetricsDto, err := validateField(c, dto.SystemMetricsDto{})
+	if err != nil {
+		return
+	}
+	systemMetrics, err := service.ListSystemMetrics(*systemMetricsDto.Window)
+	if err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	systemMetricsVos := make([]vo.SystemMetricsVo, 0, len(systemMetrics))
+	for _, item := range systemMetrics {
+		systemMetricsVos = append(systemMetricsVos, vo.SystemMetricsVo{
+			Time:        *item.PeriodTime,
+			CpuPercent:  *item.CpuPercent,
+			MemPercent:  *item.MemPercent,
+			DiskPercent: *item.DiskPercent,
+			Load1:       *item.Load1,
+			Load5:       *item.Load5,
+			Load15:      *item.Load15,
+			NetRecv:     *item.NetRecv,
+			NetSent:     *item.NetSent,
+		})
+	}
+	vo.Success(systemMetricsVos, c)
+}
@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"h-ui/model/dto"
+	"h-ui/model/entity"
+	"h-ui/model/vo"
+	"h-ui/service"
+)
+
+func PagePlan(c *gin.Context) {
+	planPageDto, err := validateField(c, dto.PlanPageDto{})
+	if err != nil {
+		return
+	}
+	plans, total, err := service.PagePlan(planPageDto)
+	if err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	planVos := make([]vo.PlanVo, 0, len(plans))
+	for _, item := range plans {
+		planVos = append(planVos, toPlanVo(item))
+	}
+	vo.Success(vo.PlanPageVo{
+		PlanVos: planVos,
+		Total:   total,
+	}, c)
+}
+
+func ListPlan(c *gin.Context) {
+	plans, err := service.ListPlan()
+	if err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	planVos := make([]vo.PlanVo, 0, len(plans))
+	for _, item := range plans {
+		planVos = append(planVos, toPlanVo(item))
+	}
+	vo.Success(planVos, c)
+}
+
+func GetPlan(c *gin.Context) {
+	idDto, err := validateField(c, dto.IdDto{})
+	if err != nil {
+		return
+	}
+	plan, err := service.GetPlan(*idDto.Id)
+	if err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	vo.Success(toPlanVo(plan), c)
+}
+
+func SavePlan(c *gin.Context) {
+	planSaveDto, err := validateField(c, dto.PlanSaveDto{})
+	if err != nil {
+		return
+	}
+	if service.ExistPlanName(*planSaveDto.Name, 0) {
+		vo.Fail(fmt.Sprintf("plan %s already exists", *planSaveDto.Name), c)
+		return
+	}
+	plan := entity.Plan{
+		Name:              planSaveDto.Name,
+		Quota:             planSaveDto.Quota,
+		Duration:          planSaveDto.Duration,
+		DeviceNo:          planSaveDto.DeviceNo,
+		TrafficMultiplier: planSaveDto.TrafficMultiplier,
+		ResetCycle:        planSaveDto.ResetCycle,
+		ResetInterval:     planSaveDto.ResetInterval,
+	}
+	if err = service.SavePlan(plan); err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	vo.Success(nil, c)
+}
+
+func UpdatePlan(c *gin.Context) {
+	planUpdateDto, err := validateField(c, dto.PlanUpdateDto{})
+	if err != nil {
+		return
+	}
+	if planUpdateDto.Name != nil && *planUpdateDto.Name != "" && service.ExistPlanName(*planUpdateDto.Name, *planUpdateDto.Id) {
+		vo.Fail(fmt.Sprintf("plan %s already exists", *planUpdateDto.Name), c)
+		return
+	}
+	plan := entity.Plan{
+		Name:              planUpdateDto.Name,
+		Quota:             planUpdateDto.Quota,
+		Duration:          planUpdateDto.Duration,
+		DeviceNo:          planUpdateDto.DeviceNo,
+		TrafficMultiplier: planUpdateDto.TrafficMultiplier,
+		ResetCycle:        planUpdateDto.ResetCycle,
+		ResetInterval:     planUpdateDto.ResetInterval,
+		BaseEntity: entity.BaseEntity{
+			Id: planUpdateDto.Id,
+		},
+	}
+	propagate := planUpdateDto.Propagate != nil && *planUpdateDto.Propagate
+	if err = service.UpdatePlan(plan, propagate); err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	vo.Success(nil, c)
+}
+
+func DeletePlan(c *gin.Context) {
+	idDto, err := validateField(c, dto.IdDto{})
+	if err != nil {
+		return
+	}
+	if err = service.DeletePlan([]int64{*idDto.Id}); err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	vo.Success(nil, c)
+}
+
+func toPlanVo(plan entity.Plan) vo.PlanVo {
+	return vo.PlanVo{
+		BaseVo: vo.BaseVo{
+			Id:         *plan.Id,
+			CreateTime: *plan.CreateTime,
+		},
+		Name:              *plan.Name,
+		Quota:             *plan.Quota,
+		Duration:          *plan.Duration,
+		DeviceNo:          *plan.DeviceNo,
+		TrafficMultiplier: *plan.TrafficMultiplier,
+		ResetCycle:        *plan.ResetCycle,
+		ResetInterval:     *plan.ResetInterval,
+	}
+}
@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"h-ui/model/constant"
+	"h-ui/model/dto"
+	"h-ui/model/vo"
+	"h-ui/service"
+	"h-ui/util"
+)
+
+func UserLogin(c *gin.Context) {
+	loginDto, err := validateField(c, dto.LoginDto{})
+	if err != nil {
+		return
+	}
+
+	if !service.ExistAccountUsername(*loginDto.Username, 0) {
+		vo.Fail("account not exist", c)
+		return
+	}
+
+	token, err := service.UserLogin(*loginDto.Username, *loginDto.Pass)
+	if err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	jwtVo := vo.JwtVo{
+		TokenType:   constant.TokenType,
+		AccessToken: token,
+	}
+	vo.Success(jwtVo, c)
+}
+
+func GetUserInfo(c *gin.Context) {
+	account, err := service.GetUserAccount(c)
+	if err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+
+	onlineUsers, err := service.Hysteria2Online()
+	if err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+
+	protocol := "http:"
+	if c.Request.TLS != nil {
+		protocol = "https:"
+	}
+	subscribeUrl, err := service.Hysteria2SubscribeUrl(*account.Id, protocol, c.Request.Host)
+	if err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+
+	userInfoVo := vo.UserInfoVo{
+		Id:           *account.Id,
+		Username:     *account.Username,
+		Quota:        *account.Quota,
+		Download:     *account.Download,
+		Upload:       *account.Upload,
+		ExpireTime:   *account.ExpireTime,
+		KickUtilTime: *account.KickUtilTime,
+		DeviceNo:     *account.DeviceNo,
+		SubscribeUrl: subscribeUrl,
+		BillingMode:  *account.BillingMode,
+	}
+	userInfoVo.BilledDownload, userInfoVo.BilledUpload = util.BilledTrafficSplit(*account.BillingMode, *account.Download, *account.Upload)
+	if value, exists := onlineUsers[*account.Username]; exists {
+		userInfoVo.Online = true
+		userInfoVo.Device = value
+	}
+	vo.Success(userInfoVo, c)
+}
+
+func UpdateUserConPass(c *gin.Context) {
+	userConPassUpdateDto, err := validateField(c, dto.UserConPassUpdateDto{})
+	if err != nil {
+		return
+	}
+	account, err := service.GetUserAccount(c)
+	if err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	if err = service.UpdateUserConPass(account, *userConPassUpdateDto.ConPass); err != nil {
+		vo.Fail(err.Error(), c)
+		return
+	}
+	vo.Success(nil, c)
+}
@@ -3,9 +3,12 @@ package controller
 import (
 	"fmt"
 	"h-ui/model/constant"
+	"h-ui/model/dto"
 	"h-ui/model/vo"
+	"h-ui/util"
 	"net/http"
-	"regexp"
+	"reflect"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -20,10 +23,7 @@ func init() {
 
 func validateStr(f validator.FieldLevel) bool {
 	field := f.Field().String()
-	// 字符串必须6-32位是字母或者数字或部分特殊字符的组合
-	reg := "^[a-zA-Z0-9!@#$%^&*()_+-=]{6,32}$"
-	compile := regexp.MustCompile(reg)
-	return field == "" || compile.MatchString(field)
+	return field == "" || util.IsValidStr(field)
 }
 
 func validateField[T interface{}](c *gin.Context, field T) (T, error) {
@@ -51,3 +51,93 @@ func validateField[T interface{}](c *gin.Context, field T) (T, error) {
 	}
 	return field, nil
 }
+
+// validateImportRow the rules of AccountUpdateDto for every row, a new account must also pass AccountSaveDto
+func validateImportRow(row dto.AccountImportDto, create bool) []string {
+	var except []string
+	if row.Pass != nil && util.IsPassHash(*row.Pass) {
+		except = append(except, "Pass")
+	}
+	if err := validate.StructExcept(&row, except...); err != nil {
+		return validationMessages(err)
+	}
+	if create {
+		accountSaveDto := dto.AccountSaveDto{
+			Username:      row.Username,
+			Pass:          row.Pass,
+			ConPass:       row.ConPass,
+			Quota:         row.Quota,
+			ExpireTime:    row.ExpireTime,
+			DeviceNo:      row.DeviceNo,
+			Deleted:       row.Deleted,
+			ResetCycle:    row.ResetCycle,
+			ResetInterval: row.ResetInterval,
+
+			TrafficMultiplier: row.TrafficMultiplier,
+			BillingMode:       row.BillingMode,
+		}
+		if err := validate.StructExcept(&accountSaveDto, except...); err != nil {
+			return validationMessages(err)
+		}
+	}
+	return nil
+}
+
+func validationMessages(err error) []string {
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return []string{constant.InvalidError}
+	}
+	var messages []string
+	for _, item := range validationErrors {
+		messages = append(messages, fmt.Sprintf("%s failed on the '%s' rule", item.Field(), item.Tag()))
+	}
+	return messages
+}
+
+// validationFieldErrors the struct tag errors addressed by the json path of the field
+func validationFieldErrors(root any, err error) []vo.FieldErrorVo {
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return []vo.FieldErrorVo{{Message: constant.InvalidError}}
+	}
+	var fieldErrors []vo.FieldErrorVo
+	for _, item := range validationErrors {
+		fieldErrors = append(fieldErrors, vo.FieldErrorVo{
+			Field:   jsonPath(reflect.TypeOf(root), item.StructNamespace()),
+			Message: fmt.Sprintf("failed on the '%s' rule", item.Tag()),
+		})
+	}
+	return fieldErrors
+}
+
+// jsonPath convert the struct namespace Hysteria2ServerConfig.Outbounds[0].Name to outbounds[0].name
+func jsonPath(t reflect.Type, namespace string) string {
+	var path []string
+	segments := strings.Split(namespace, ".")
+	for _, segment := range segments[1:] {
+		name, index, _ := strings.Cut(segment, "[")
+		if index != "" {
+			index = "[" + index
+		}
+		for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			path = append(path, segment)
+			continue
+		}
+		field, ok := t.FieldByName(name)
+		if !ok {
+			path = append(path, segment)
+			continue
+		}
+		jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if jsonName == "" || jsonName == "-" {
+			jsonName = name
+		}
+		path = append(path, jsonName+index)
+		t = field.Type
+	}
+	return strings.Join(path, ".")
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 	"h-ui/model/constant"
 	"h-ui/model/dto"
 	"h-ui/model/entity"
@@ -20,9 +19,21 @@ func SaveAccount(account entity.Account) (int64, error) {
 	return *account.Id, nil
 }
 
-func DeleteAccount(ids []int64) error {
-	if tx := sqliteDB.Where("id in ?", ids).Delete(&entity.Account{}); tx.Error != nil {
-		logrus.Errorf("%v", tx.Error)
+// PurgeAccount delete the accounts with their tags, alerts and traffic history in one transaction
+func PurgeAccount(ids []int64) error {
+	if err := sqliteDB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id in ?", ids).Delete(&entity.Account{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("account_id in ?", ids).Delete(&entity.AccountTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("account_id in ?", ids).Delete(&entity.AccountAlert{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("account_id in ?", ids).Delete(&entity.AccountTraffic{}).Error
+	}); err != nil {
+		logrus.Errorf("%v", err)
 		return errors.New(constant.SysError)
 	}
 	return nil
@@ -41,17 +52,50 @@ func UpdateAccount(ids []int64, updates map[string]interface{}) error {
 	return nil
 }
 
-func UpsertAccount(accounts []entity.Account) error {
-	if tx := sqliteDB.Model(&entity.Account{}).Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: "username"}},
-		DoUpdates: clause.AssignmentColumns([]string{"pass", "con_pass", "quota", "download", "upload", "expire_time", "kick_util_time", "device_no", "role", "deleted", "create_time", "update_time", "login_at", "con_at"}),
-	}).Create(accounts); tx.Error != nil {
-		logrus.Errorf("%v", tx.Error)
+// UpdateAccountEach apply the updates of every account id in one transaction
+func UpdateAccountEach(updates map[int64]map[string]interface{}) error {
+	now := time.Now().Format("2006-01-02 15:04:05")
+	if err := sqliteDB.Transaction(func(tx *gorm.DB) error {
+		for id, item := range updates {
+			item["update_time"] = now
+			if err := tx.Model(&entity.Account{}).Where("id = ?", id).Updates(item).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		logrus.Errorf("%v", err)
 		return errors.New(constant.SysError)
 	}
 	return nil
 }
 
+// AccountBilledTraffic the traffic of the account counted against its quota by the billing mode, see util.BilledTraffic
+const AccountBilledTraffic = "(CASE billing_mode WHEN 'download' THEN download WHEN 'upload' THEN upload " +
+	"WHEN 'max' THEN max(download, upload) ELSE download + upload END)"
+
+// ListTrafficMultiplier username -> traffic multiplier of the account, or of the plan it is on when it has none
+func ListTrafficMultiplier() (map[string]float64, error) {
+	var rows []struct {
+		Username          string
+		TrafficMultiplier float64
+	}
+	if tx := sqliteDB.Model(&entity.Account{}).
+		Select("account.username, CASE WHEN account.traffic_multiplier > 0 THEN account.traffic_multiplier " +
+			"ELSE plan.traffic_multiplier END as traffic_multiplier").
+		Joins("left join plan on plan.id = account.plan_id").
+		Where("account.traffic_multiplier > 0 or plan.traffic_multiplier > 0").
+		Scan(&rows); tx.Error != nil {
+		logrus.Errorf("%v", tx.Error)
+		return nil, errors.New(constant.SysError)
+	}
+	multipliers := make(map[string]float64, len(rows))
+	for _, row := range rows {
+		multipliers[row.Username] = row.TrafficMultiplier
+	}
+	return multipliers, nil
+}
+
 func UpdateAccountTraffic(username string, download int64, upload int64) error {
 	if upload != 0 || download != 0 {
 		updates := map[string]interface{}{}
@@ -85,19 +129,30 @@ func GetAccount(query interface{}, args ...interface{}) (entity.Account, error)
 	return account, nil
 }
 
-func PageAccount(accountPageDto dto.AccountPageDto) ([]entity.Account, int64, error) {
+// PageAccount trashed lists the accounts in the trash instead of the active ones
+func PageAccount(accountPageDto dto.AccountPageDto, trashed bool) ([]entity.Account, int64, error) {
 	var accounts []entity.Account
 	var total int64
 	tx := sqliteDB.Model(&entity.Account{})
+	order := "role,create_time desc"
+	if trashed {
+		tx.Where("trashed_at > 0")
+		order = "trashed_at desc"
+	} else {
+		tx.Where("trashed_at = 0")
+	}
 	if accountPageDto.Username != nil && *accountPageDto.Username != "" {
 		tx.Where("username like ?", fmt.Sprintf("%%%s%%", *accountPageDto.Username))
 	}
 	if accountPageDto.Deleted != nil {
 		tx.Where("deleted = ?", *accountPageDto.Deleted)
 	}
+	if accountPageDto.Tag != nil && *accountPageDto.Tag != "" {
+		tx.Where("id in (select account_id from account_tag where tag = ?)", *accountPageDto.Tag)
+	}
 	tx.Count(&total)
 	if tx.Scopes(Paginate(accountPageDto.PageNum, accountPageDto.PageSize)).
-		Order("role,create_time desc").
+		Order(order).
 		Find(&accounts); tx.Error != nil {
 		logrus.Errorf("%v", tx.Error)
 		return accounts, 0, errors.New(constant.SysError)
@@ -114,3 +169,37 @@ func ListAccount(query interface{}, args ...interface{}) ([]entity.Account, erro
 	}
 	return accounts, nil
 }
+
+// CreateAccount insert every account or none of them
+func CreateAccount(accounts []entity.Account) error {
+	if err := sqliteDB.Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(&accounts, 100).Error
+	}); err != nil {
+		logrus.Errorf("%v", err)
+		return errors.New(constant.SysError)
+	}
+	return nil
+}
+
+// ImportAccount create and update the accounts in one transaction
+func ImportAccount(creates []entity.Account, updates map[int64]map[string]interface{}) error {
+	now := time.Now().Format("2006-01-02 15:04:05")
+	if err := sqliteDB.Transaction(func(tx *gorm.DB) error {
+		if len(creates) > 0 {
+			if err := tx.CreateInBatches(&creates, 100).Error; err != nil {
+				return err
+			}
+		}
+		for id, item := range updates {
+			item["update_time"] = now
+			if err := tx.Model(&entity.Account{}).Where("id = ?", id).Updates(item).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		logrus.Errorf("%v", err)
+		return errors.New(constant.SysError)
+	}
+	return nil
+}
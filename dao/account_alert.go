@@ -0,0 +1,36 @@
+package dao
+
+import (
+	"errors"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm/clause"
+	"h-ui/model/constant"
+	"h-ui/model/entity"
+)
+
+func SaveAccountAlert(accountAlerts []entity.AccountAlert) error {
+	if tx := sqliteDB.Clauses(clause.OnConflict{DoNothing: true}).
+		CreateInBatches(&accountAlerts, 100); tx.Error != nil {
+		logrus.Errorf("%v", tx.Error)
+		return errors.New(constant.SysError)
+	}
+	return nil
+}
+
+func DeleteAccountAlert(query interface{}, args ...interface{}) error {
+	if tx := sqliteDB.Where(query, args...).Delete(&entity.AccountAlert{}); tx.Error != nil {
+		logrus.Errorf("%v", tx.Error)
+		return errors.New(constant.SysError)
+	}
+	return nil
+}
+
+func ListAccountAlert(query interface{}, args ...interface{}) ([]entity.AccountAlert, error) {
+	var accountAlerts []entity.AccountAlert
+	if tx := sqliteDB.Model(&entity.AccountAlert{}).
+		Where(query, args...).Find(&accountAlerts); tx.Error != nil {
+		logrus.Errorf("%v", tx.Error)
+		return accountAlerts, errors.New(constant.SysError)
+	}
+	return accountAlerts, nil
+}
@@ -0,0 +1,95 @@
+package dao
+
+import (
+	"errors"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"h-ui/model/bo"
+	"h-ui/model/constant"
+	"h-ui/model/entity"
+	"time"
+)
+
+// tagAccountQuery the user accounts of the tag that are not in the trash, a tag-wide action never touches the admin
+const tagAccountQuery = "role = 'user' and trashed_at = 0 and id in (select account_id from account_tag where tag = ?)"
+
+// SaveAccountTag tag every account with every tag, existing pairs are ignored
+func SaveAccountTag(ids []int64, tags []string) error {
+	var accountTags []entity.AccountTag
+	for _, id := range ids {
+		for _, tag := range tags {
+			accountId, accountTag := id, tag
+			accountTags = append(accountTags, entity.AccountTag{AccountId: &accountId, Tag: &accountTag})
+		}
+	}
+	if tx := sqliteDB.Clauses(clause.OnConflict{DoNothing: true}).
+		CreateInBatches(&accountTags, 100); tx.Error != nil {
+		logrus.Errorf("%v", tx.Error)
+		return errors.New(constant.SysError)
+	}
+	return nil
+}
+
+func DeleteAccountTag(query interface{}, args ...interface{}) error {
+	if tx := sqliteDB.Where(query, args...).Delete(&entity.AccountTag{}); tx.Error != nil {
+		logrus.Errorf("%v", tx.Error)
+		return errors.New(constant.SysError)
+	}
+	return nil
+}
+
+func ListAccountTag(query interface{}, args ...interface{}) ([]entity.AccountTag, error) {
+	var accountTags []entity.AccountTag
+	if tx := sqliteDB.Model(&entity.AccountTag{}).
+		Where(query, args...).Order("tag").Find(&accountTags); tx.Error != nil {
+		logrus.Errorf("%v", tx.Error)
+		return accountTags, errors.New(constant.SysError)
+	}
+	return accountTags, nil
+}
+
+// ListAccountTagCount every tag with the number of its accounts that are not in the trash
+func ListAccountTagCount() ([]bo.AccountTagCount, error) {
+	var accountTagCounts []bo.AccountTagCount
+	if tx := sqliteDB.Model(&entity.AccountTag{}).
+		Select("tag, count(*) as count").
+		Where("account_id in (select id from account where trashed_at = 0)").
+		Group("tag").
+		Order("tag").
+		Scan(&accountTagCounts); tx.Error != nil {
+		logrus.Errorf("%v", tx.Error)
+		return accountTagCounts, errors.New(constant.SysError)
+	}
+	return accountTagCounts, nil
+}
+
+// UpdateTagAccount update every account of the tag in one transaction, the usernames of the updated accounts are returned
+func UpdateTagAccount(tag string, updates map[string]interface{}) ([]string, error) {
+	var usernames []string
+	updates["update_time"] = time.Now().Format("2006-01-02 15:04:05")
+	if err := sqliteDB.Transaction(func(tx *gorm.DB) error {
+		var ids []int64
+		if err := tx.Model(&entity.Account{}).Where(tagAccountQuery, tag).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := tx.Model(&entity.Account{}).Where("id in ?", ids).Updates(updates).Error; err != nil {
+			return err
+		}
+		return tx.Model(&entity.Account{}).Where("id in ?", ids).Pluck("username", &usernames).Error
+	}); err != nil {
+		logrus.Errorf("%v", err)
+		return nil, errors.New(constant.SysError)
+	}
+	return usernames, nil
+}
+
+// ExtendTagAccountExpire extend the expiry of every account of the tag, an expired account is extended from now
+func ExtendTagAccountExpire(tag string, now int64, duration int64) ([]string, error) {
+	return UpdateTagAccount(tag, map[string]interface{}{
+		"expire_time": gorm.Expr("max(expire_time, ?) + ?", now, duration),
+	})
+}
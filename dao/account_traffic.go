@@ -0,0 +1,61 @@
+package dao
+
+import (
+	"errors"
+	"github.com/sirupsen/logrus"
+	"h-ui/model/constant"
+	"h-ui/model/entity"
+)
+
+// UpsertAccountTraffic add the traffic delta of the account to the bucket
+func UpsertAccountTraffic(username string, period string, periodTime int64, download int64, upload int64) error {
+	if download == 0 && upload == 0 {
+		return nil
+	}
+	if tx := sqliteDB.Exec("INSERT INTO account_traffic (account_id, period, period_time, download, upload) "+
+		"SELECT id, ?, ?, ?, ? FROM account WHERE username = ? "+
+		"ON CONFLICT (account_id, period, period_time) DO UPDATE SET "+
+		"download = download + excluded.download, upload = upload + excluded.upload, update_time = CURRENT_TIMESTAMP",
+		period, periodTime, download, upload, username); tx.Error != nil {
+		logrus.Errorf("%v", tx.Error)
+		return errors.New(constant.SysError)
+	}
+	return nil
+}
+
+// ListAccountTraffic the buckets of the period, summed across the matching accounts
+func ListAccountTraffic(query interface{}, args ...interface{}) ([]entity.AccountTraffic, error) {
+	var accountTraffics []entity.AccountTraffic
+	if tx := sqliteDB.Model(&entity.AccountTraffic{}).
+		Select("period, period_time, sum(download) as download, sum(upload) as upload").
+		Where(query, args...).
+		Group("period, period_time").
+		Order("period_time").
+		Find(&accountTraffics); tx.Error != nil {
+		logrus.Errorf("%v", tx.Error)
+		return accountTraffics, errors.New(constant.SysError)
+	}
+	return accountTraffics, nil
+}
+
+// SumAccountTraffic the buckets summed by account
+func SumAccountTraffic(query interface{}, args ...interface{}) ([]entity.AccountTraffic, error) {
+	var accountTraffics []entity.AccountTraffic
+	if tx := sqliteDB.Model(&entity.AccountTraffic{}).
+		Select("account_id, sum(download) as download, sum(upload) as upload").
+		Where(query, args...).
+		Group("account_id").
+		Find(&accountTraffics); tx.Error != nil {
+		logrus.Errorf("%v", tx.Error)
+		return accountTraffics, errors.New(constant.SysError)
+	}
+	return accountTraffics, nil
+}
+
+func DeleteAccountTraffic(query interface{}, args ...interface{}) error {
+	if tx := sqliteDB.Where(query, args...).Delete(&entity.AccountTraffic{}); tx.Error != nil {
+		logrus.Errorf("%v", tx.Error)
+		return errors.New(constant.SysError)
+	}
+	return nil
+}
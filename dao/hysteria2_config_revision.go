@@ -0,0 +1,88 @@
+package dao
+
+import (
+	"errors"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"h-ui/model/constant"
+	"h-ui/model/dto"
+	"h-ui/model/entity"
+	"time"
+)
+
+// hysteria2ConfigRevisionMax the number of revisions that are kept
+const hysteria2ConfigRevisionMax = 100
+
+// UpdateHysteria2ConfigRevision update HYSTERIA2_CONFIG and record the revision in one transaction. The config saved
+// before the revisions were recorded becomes the first revision, so the first change can be rolled back too
+func UpdateHysteria2ConfigRevision(revision entity.Hysteria2ConfigRevision) error {
+	if err := sqliteDB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&entity.Hysteria2ConfigRevision{}).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			var config entity.Config
+			if err := tx.Where("key = ?", constant.Hysteria2Config).First(&config).Error; err != nil {
+				return err
+			}
+			if config.Value != nil && *config.Value != "" {
+				diff, operator, remark := "", "system", "baseline before the first change"
+				if err := tx.Create(&entity.Hysteria2ConfigRevision{
+					Config:   config.Value,
+					Diff:     &diff,
+					Operator: &operator,
+					Remark:   &remark,
+				}).Error; err != nil {
+					return err
+				}
+			}
+		}
+		if err := tx.Model(&entity.Config{}).
+			Where("key = ?", constant.Hysteria2Config).
+			Updates(map[string]interface{}{
+				"value":       *revision.Config,
+				"update_time": time.Now().Format("2006-01-02 15:04:05"),
+			}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&revision).Error; err != nil {
+			return err
+		}
+		return tx.Where("id <= ?", *revision.Id-hysteria2ConfigRevisionMax).
+			Delete(&entity.Hysteria2ConfigRevision{}).Error
+	}); err != nil {
+		logrus.Errorf("%v", err)
+		return errors.New(constant.SysError)
+	}
+	return nil
+}
+
+func GetHysteria2ConfigRevision(query interface{}, args ...interface{}) (entity.Hysteria2ConfigRevision, error) {
+	var revision entity.Hysteria2ConfigRevision
+	if tx := sqliteDB.Model(&entity.Hysteria2ConfigRevision{}).
+		Where(query, args...).First(&revision); tx.Error != nil {
+		if tx.Error == gorm.ErrRecordNotFound {
+			return revision, errors.New("revision not exist")
+		}
+		logrus.Errorf("%v", tx.Error)
+		return revision, errors.New(constant.SysError)
+	}
+	return revision, nil
+}
+
+// PageHysteria2ConfigRevision the revisions without the config and the diff
+func PageHysteria2ConfigRevision(baseDto dto.BaseDto) ([]entity.Hysteria2ConfigRevision, int64, error) {
+	var revisions []entity.Hysteria2ConfigRevision
+	var total int64
+	tx := sqliteDB.Model(&entity.Hysteria2ConfigRevision{})
+	tx.Count(&total)
+	if tx.Select("id, operator, remark, create_time, update_time").
+		Scopes(Paginate(baseDto.PageNum, baseDto.PageSize)).
+		Order("id desc").
+		Find(&revisions); tx.Error != nil {
+		logrus.Errorf("%v", tx.Error)
+		return revisions, 0, errors.New(constant.SysError)
+	}
+	return revisions, total, nil
+}
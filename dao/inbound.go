@@ -0,0 +1,81 @@
+package dao
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"h-ui/model/constant"
+	"h-ui/model/dto"
+	"h-ui/model/entity"
+	"time"
+)
+
+func SaveInbound(inbound entity.Inbound) (int64, error) {
+	if tx := sqliteDB.Save(&inbound); tx.Error != nil {
+		logrus.Errorf("%v", tx.Error)
+		return 0, errors.New(constant.SysError)
+	}
+	return *inbound.Id, nil
+}
+
+func DeleteInbound(ids []int64) error {
+	if tx := sqliteDB.Where("id in ?", ids).Delete(&entity.Inbound{}); tx.Error != nil {
+		logrus.Errorf("%v", tx.Error)
+		return errors.New(constant.SysError)
+	}
+	return nil
+}
+
+func UpdateInbound(ids []int64, updates map[string]interface{}) error {
+	if len(updates) > 0 {
+		updates["update_time"] = time.Now().Format("2006-01-02 15:04:05")
+		if tx := sqliteDB.Model(&entity.Inbound{}).
+			Where("id in ?", ids).
+			Updates(updates); tx.Error != nil {
+			logrus.Errorf("%v", tx.Error)
+			return errors.New(constant.SysError)
+		}
+	}
+	return nil
+}
+
+func GetInbound(query interface{}, args ...interface{}) (entity.Inbound, error) {
+	var inbound entity.Inbound
+	if tx := sqliteDB.Model(&entity.Inbound{}).
+		Where(query, args...).First(&inbound); tx.Error != nil {
+		if tx.Error == gorm.ErrRecordNotFound {
+			return inbound, errors.New("inbound not exist")
+		}
+		logrus.Errorf("%v", tx.Error)
+		return inbound, errors.New(constant.SysError)
+	}
+	return inbound, nil
+}
+
+func PageInbound(inboundPageDto dto.InboundPageDto) ([]entity.Inbound, int64, error) {
+	var inbounds []entity.Inbound
+	var total int64
+	tx := sqliteDB.Model(&entity.Inbound{})
+	if inboundPageDto.Name != nil && *inboundPageDto.Name != "" {
+		tx.Where("name like ?", fmt.Sprintf("%%%s%%", *inboundPageDto.Name))
+	}
+	tx.Count(&total)
+	if tx.Scopes(Paginate(inboundPageDto.PageNum, inboundPageDto.PageSize)).
+		Order("id asc").
+		Find(&inbounds); tx.Error != nil {
+		logrus.Errorf("%v", tx.Error)
+		return inbounds, 0, errors.New(constant.SysError)
+	}
+	return inbounds, total, nil
+}
+
+func ListInbound(query interface{}, args ...interface{}) ([]entity.Inbound, error) {
+	var inbounds []entity.Inbound
+	if tx := sqliteDB.Model(&entity.Inbound{}).
+		Where(query, args...).Order("id asc").Find(&inbounds); tx.Error != nil {
+		logrus.Errorf("%v", tx.Error)
+		return inbounds, errors.New(constant.SysError)
+	}
+	return inbounds, nil
+}
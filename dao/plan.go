@@ -0,0 +1,98 @@
+package dao
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"h-ui/model/constant"
+	"h-ui/model/dto"
+	"h-ui/model/entity"
+	"time"
+)
+
+func SavePlan(plan entity.Plan) (int64, error) {
+	if tx := sqliteDB.Save(&plan); tx.Error != nil {
+		logrus.Errorf("%v", tx.Error)
+		return 0, errors.New(constant.SysError)
+	}
+	return *plan.Id, nil
+}
+
+func DeletePlan(ids []int64) error {
+	return sqliteDB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.Account{}).
+			Where("plan_id in ?", ids).
+			Updates(map[string]interface{}{"plan_id": 0, "update_time": time.Now().Format("2006-01-02 15:04:05")}).Error; err != nil {
+			logrus.Errorf("%v", err)
+			return errors.New(constant.SysError)
+		}
+		if err := tx.Where("id in ?", ids).Delete(&entity.Plan{}).Error; err != nil {
+			logrus.Errorf("%v", err)
+			return errors.New(constant.SysError)
+		}
+		return nil
+	})
+}
+
+func GetPlan(query interface{}, args ...interface{}) (entity.Plan, error) {
+	var plan entity.Plan
+	if tx := sqliteDB.Model(&entity.Plan{}).
+		Where(query, args...).First(&plan); tx.Error != nil {
+		if tx.Error == gorm.ErrRecordNotFound {
+			return plan, errors.New("plan not exist")
+		}
+		logrus.Errorf("%v", tx.Error)
+		return plan, errors.New(constant.SysError)
+	}
+	return plan, nil
+}
+
+func PagePlan(planPageDto dto.PlanPageDto) ([]entity.Plan, int64, error) {
+	var plans []entity.Plan
+	var total int64
+	tx := sqliteDB.Model(&entity.Plan{})
+	if planPageDto.Name != nil && *planPageDto.Name != "" {
+		tx.Where("name like ?", fmt.Sprintf("%%%s%%", *planPageDto.Name))
+	}
+	tx.Count(&total)
+	if tx.Scopes(Paginate(planPageDto.PageNum, planPageDto.PageSize)).
+		Order("create_time desc").
+		Find(&plans); tx.Error != nil {
+		logrus.Errorf("%v", tx.Error)
+		return plans, 0, errors.New(constant.SysError)
+	}
+	return plans, total, nil
+}
+
+func ListPlan(query interface{}, args ...interface{}) ([]entity.Plan, error) {
+	var plans []entity.Plan
+	if tx := sqliteDB.Model(&entity.Plan{}).
+		Where(query, args...).Order("create_time desc").Find(&plans); tx.Error != nil {
+		logrus.Errorf("%v", tx.Error)
+		return plans, errors.New(constant.SysError)
+	}
+	return plans, nil
+}
+
+// UpdatePlanAndAccount update the plan and every account on it in one transaction
+func UpdatePlanAndAccount(id int64, planUpdates map[string]interface{}, accountUpdates map[string]interface{}) error {
+	now := time.Now().Format("2006-01-02 15:04:05")
+	return sqliteDB.Transaction(func(tx *gorm.DB) error {
+		if len(planUpdates) > 0 {
+			planUpdates["update_time"] = now
+			if err := tx.Model(&entity.Plan{}).Where("id = ?", id).Updates(planUpdates).Error; err != nil {
+				logrus.Errorf("%v", err)
+				return errors.New(constant.SysError)
+			}
+		}
+		if len(accountUpdates) > 0 {
+			accountUpdates["update_time"] = now
+			if err := tx.Model(&entity.Account{}).Where("plan_id = ?", id).Updates(accountUpdates).Error; err != nil {
+				logrus.Errorf("%v", err)
+				return errors.New(constant.SysError)
+			}
+		}
+		return nil
+	})
+}
@@ -16,7 +16,7 @@ import (
 	"gorm.io/gorm/schema"
 )
 
-var sqlInitStr = "CREATE TABLE IF NOT EXISTS account\n(\n    id             INTEGER PRIMARY KEY AUTOINCREMENT,\n    username       TEXT    NOT NULL UNIQUE DEFAULT '',\n    pass           TEXT    NOT NULL        DEFAULT '',\n    con_pass       TEXT    NOT NULL        DEFAULT '',\n    quota          INTEGER NOT NULL        DEFAULT 0,\n    download       INTEGER NOT NULL        DEFAULT 0,\n    upload         INTEGER NOT NULL        DEFAULT 0,\n    expire_time    INTEGER NOT NULL        DEFAULT 0,\n    kick_util_time INTEGER NOT NULL        DEFAULT 0,\n    device_no      INTEGER NOT NULL        DEFAULT 3,\n    role           TEXT    NOT NULL        DEFAULT 'user',\n    deleted        INTEGER NOT NULL        DEFAULT 0,\n    create_time    TIMESTAMP               DEFAULT CURRENT_TIMESTAMP,\n    update_time    TIMESTAMP               DEFAULT CURRENT_TIMESTAMP\n);\nALTER TABLE account\n    ADD COLUMN login_at INTEGER NOT NULL DEFAULT 0;\nALTER TABLE account\n    ADD COLUMN con_at INTEGER NOT NULL DEFAULT 0;\nCREATE INDEX IF NOT EXISTS account_deleted_index ON account (deleted);\nCREATE INDEX IF NOT EXISTS account_username_index ON account (username);\nCREATE INDEX IF NOT EXISTS account_con_pass_index ON account (con_pass);\nCREATE INDEX IF NOT EXISTS account_pass_index ON account (pass);\nINSERT INTO account (id, username, pass, con_pass, quota, download, upload, expire_time, device_no, role)\nSELECT 1 ,'sysadmin', '02f382b76ca1ab7aa06ab03345c7712fd5b971fb0c0f2aef98bac9cd', 'sysadmin.sysadmin', -1, 0, 0, 253370736000000, 6, 'admin'\n    WHERE NOT EXISTS (SELECT 1 FROM account WHERE id = 1);\nCREATE TABLE IF NOT EXISTS config\n(\n    id          INTEGER PRIMARY KEY AUTOINCREMENT,\n    key         TEXT NOT NULL UNIQUE DEFAULT '',\n    value       TEXT NOT NULL        DEFAULT '',\n    remark      TEXT NOT NULL        DEFAULT '',\n    create_time TIMESTAMP            DEFAULT CURRENT_TIMESTAMP,\n    update_time TIMESTAMP            DEFAULT CURRENT_TIMESTAMP\n);\nCREATE INDEX IF NOT EXISTS config_key_index ON config (key);\nINSERT INTO config (key, value, remark)\nSELECT 'H_UI_WEB_PORT', '8081', 'H UI Web Port'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'H_UI_WEB_PORT');\nINSERT INTO config (key, value, remark)\nSELECT 'H_UI_WEB_CONTEXT', '/', 'H UI Web Context'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'H_UI_WEB_CONTEXT');\nINSERT INTO config (key, value, remark)\nSELECT 'H_UI_CRT_PATH', '', 'H UI Crt File Path'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'H_UI_CRT_PATH');\nINSERT INTO config (key, value, remark)\nSELECT 'H_UI_KEY_PATH', '', 'H UI Key File Path'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'H_UI_KEY_PATH');\nINSERT INTO config (key, value, remark)\nSELECT 'JWT_SECRET', hex(randomblob(10)), 'JWT Secret'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'JWT_SECRET');\nINSERT INTO config (key, value, remark)\nSELECT 'HYSTERIA2_ENABLE', '0', 'Hysteria2 Switch'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'HYSTERIA2_ENABLE');\nINSERT INTO config (key, value, remark)\nSELECT 'HYSTERIA2_CONFIG', '', 'Hysteria2 Config'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'HYSTERIA2_CONFIG');\nINSERT INTO config (key, value, remark)\nSELECT 'HYSTERIA2_TRAFFIC_TIME', '1', 'Hysteria2 Traffic Time'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'HYSTERIA2_TRAFFIC_TIME');\nINSERT INTO config (key, value, remark)\nSELECT 'HYSTERIA2_CONFIG_REMARK', '', 'Hysteria2 Config Remark'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'HYSTERIA2_CONFIG_REMARK');\nINSERT INTO config (key, value, remark)\nSELECT 'HYSTERIA2_CONFIG_PORT_HOPPING', '', 'Hysteria2 Config Port Hopping'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'HYSTERIA2_CONFIG_PORT_HOPPING');\nINSERT INTO config (key, value, remark)\nSELECT 'RESET_TRAFFIC_CRON', '', 'Reset Traffic Cron'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'RESET_TRAFFIC_CRON');\nINSERT INTO config (key, value, remark)\nSELECT 'TELEGRAM_ENABLE', '0', 'Telegram Switch'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'TELEGRAM_ENABLE');\nINSERT INTO config (key, value, remark)\nSELECT 'TELEGRAM_TOKEN', '', 'Telegram Token'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'TELEGRAM_TOKEN');\nINSERT INTO config (key, value, remark)\nSELECT 'TELEGRAM_CHAT_ID', '', 'Telegram ChatId'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'TELEGRAM_CHAT_ID');\nINSERT INTO config (key, value, remark)\nSELECT 'TELEGRAM_LOGIN_JOB_ENABLE', '0', 'TELEGRAM LOGIN Notification'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'TELEGRAM_LOGIN_JOB_ENABLE');\nINSERT INTO config (key, value, remark)\nSELECT 'TELEGRAM_LOGIN_JOB_TEXT', '[time], [username] logged into the panel, IP address is [ip]', 'TELEGRAM LOGIN Notification Text'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'TELEGRAM_LOGIN_JOB_TEXT');\nINSERT INTO config (key, value, remark)\nSELECT 'CLASH_EXTENSION', '', 'Clash Subscription Extension'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'CLASH_EXTENSION');"
+var sqlInitStr = "CREATE TABLE IF NOT EXISTS account\n(\n    id             INTEGER PRIMARY KEY AUTOINCREMENT,\n    username       TEXT    NOT NULL UNIQUE DEFAULT '',\n    pass           TEXT    NOT NULL        DEFAULT '',\n    con_pass       TEXT    NOT NULL        DEFAULT '',\n    quota          INTEGER NOT NULL        DEFAULT 0,\n    download       INTEGER NOT NULL        DEFAULT 0,\n    upload         INTEGER NOT NULL        DEFAULT 0,\n    expire_time    INTEGER NOT NULL        DEFAULT 0,\n    kick_util_time INTEGER NOT NULL        DEFAULT 0,\n    device_no      INTEGER NOT NULL        DEFAULT 3,\n    role           TEXT    NOT NULL        DEFAULT 'user',\n    deleted        INTEGER NOT NULL        DEFAULT 0,\n    create_time    TIMESTAMP               DEFAULT CURRENT_TIMESTAMP,\n    update_time    TIMESTAMP               DEFAULT CURRENT_TIMESTAMP\n);\nALTER TABLE account\n    ADD COLUMN login_at INTEGER NOT NULL DEFAULT 0;\nALTER TABLE account\n    ADD COLUMN con_at INTEGER NOT NULL DEFAULT 0;\nCREATE INDEX IF NOT EXISTS account_deleted_index ON account (deleted);\nCREATE INDEX IF NOT EXISTS account_username_index ON account (username);\nCREATE INDEX IF NOT EXISTS account_con_pass_index ON account (con_pass);\nCREATE INDEX IF NOT EXISTS account_pass_index ON account (pass);\nINSERT INTO account (id, username, pass, con_pass, quota, download, upload, expire_time, device_no, role)\nSELECT 1 ,'sysadmin', '02f382b76ca1ab7aa06ab03345c7712fd5b971fb0c0f2aef98bac9cd', 'sysadmin.sysadmin', -1, 0, 0, 253370736000000, 6, 'admin'\n    WHERE NOT EXISTS (SELECT 1 FROM account WHERE id = 1);\nCREATE TABLE IF NOT EXISTS config\n(\n    id          INTEGER PRIMARY KEY AUTOINCREMENT,\n    key         TEXT NOT NULL UNIQUE DEFAULT '',\n    value       TEXT NOT NULL        DEFAULT '',\n    remark      TEXT NOT NULL        DEFAULT '',\n    create_time TIMESTAMP            DEFAULT CURRENT_TIMESTAMP,\n    update_time TIMESTAMP            DEFAULT CURRENT_TIMESTAMP\n);\nCREATE INDEX IF NOT EXISTS config_key_index ON config (key);\nINSERT INTO config (key, value, remark)\nSELECT 'H_UI_WEB_PORT', '8081', 'H UI Web Port'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'H_UI_WEB_PORT');\nINSERT INTO config (key, value, remark)\nSELECT 'H_UI_WEB_CONTEXT', '/', 'H UI Web Context'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'H_UI_WEB_CONTEXT');\nINSERT INTO config (key, value, remark)\nSELECT 'H_UI_CRT_PATH', '', 'H UI Crt File Path'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'H_UI_CRT_PATH');\nINSERT INTO config (key, value, remark)\nSELECT 'H_UI_KEY_PATH', '', 'H UI Key File Path'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'H_UI_KEY_PATH');\nINSERT INTO config (key, value, remark)\nSELECT 'JWT_SECRET', hex(randomblob(10)), 'JWT Secret'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'JWT_SECRET');\nINSERT INTO config (key, value, remark)\nSELECT 'HYSTERIA2_ENABLE', '0', 'Hysteria2 Switch'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'HYSTERIA2_ENABLE');\nINSERT INTO config (key, value, remark)\nSELECT 'HYSTERIA2_CONFIG', '', 'Hysteria2 Config'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'HYSTERIA2_CONFIG');\nINSERT INTO config (key, value, remark)\nSELECT 'HYSTERIA2_TRAFFIC_TIME', '1', 'Hysteria2 Traffic Time'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'HYSTERIA2_TRAFFIC_TIME');\nINSERT INTO config (key, value, remark)\nSELECT 'HYSTERIA2_CONFIG_REMARK', '', 'Hysteria2 Config Remark'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'HYSTERIA2_CONFIG_REMARK');\nINSERT INTO config (key, value, remark)\nSELECT 'HYSTERIA2_CONFIG_PORT_HOPPING', '', 'Hysteria2 Config Port Hopping'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'HYSTERIA2_CONFIG_PORT_HOPPING');\nINSERT INTO config (key, value, remark)\nSELECT 'RESET_TRAFFIC_CRON', '', 'Reset Traffic Cron'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'RESET_TRAFFIC_CRON');\nINSERT INTO config (key, value, remark)\nSELECT 'TELEGRAM_ENABLE', '0', 'Telegram Switch'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'TELEGRAM_ENABLE');\nINSERT INTO config (key, value, remark)\nSELECT 'TELEGRAM_TOKEN', '', 'Telegram Token'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'TELEGRAM_TOKEN');\nINSERT INTO config (key, value, remark)\nSELECT 'TELEGRAM_CHAT_ID', '', 'Telegram ChatId'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'TELEGRAM_CHAT_ID');\nINSERT INTO config (key, value, remark)\nSELECT 'TELEGRAM_LOGIN_JOB_ENABLE', '0', 'TELEGRAM LOGIN Notification'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'TELEGRAM_LOGIN_JOB_ENABLE');\nINSERT INTO config (key, value, remark)\nSELECT 'TELEGRAM_LOGIN_JOB_TEXT', '[time], [username] logged into the panel, IP address is [ip]', 'TELEGRAM LOGIN Notification Text'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'TELEGRAM_LOGIN_JOB_TEXT');\nINSERT INTO config (key, value, remark)\nSELECT 'CLASH_EXTENSION', '', 'Clash Subscription Extension'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'CLASH_EXTENSION');;\nCREATE TABLE IF NOT EXISTS account_traffic\n(\n    id          INTEGER PRIMARY KEY AUTOINCREMENT,\n    account_id  INTEGER NOT NULL DEFAULT 0,\n    period      TEXT    NOT NULL DEFAULT 'hour',\n    period_time INTEGER NOT NULL DEFAULT 0,\n    download    INTEGER NOT NULL DEFAULT 0,\n    upload      INTEGER NOT NULL DEFAULT 0,\n    create_time TIMESTAMP        DEFAULT CURRENT_TIMESTAMP,\n    update_time TIMESTAMP        DEFAULT CURRENT_TIMESTAMP\n);\nCREATE UNIQUE INDEX IF NOT EXISTS account_traffic_unique_index ON account_traffic (account_id, period, period_time);\nCREATE INDEX IF NOT EXISTS account_traffic_period_time_index ON account_traffic (period, period_time);\nINSERT INTO config (key, value, remark)\nSELECT 'ACCOUNT_TRAFFIC_RETENTION', '90', 'Account Traffic History Retention Days'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'ACCOUNT_TRAFFIC_RETENTION');\nCREATE TABLE IF NOT EXISTS plan\n(\n    id                 INTEGER PRIMARY KEY AUTOINCREMENT,\n    name               TEXT    NOT NULL UNIQUE DEFAULT '',\n    quota              INTEGER NOT NULL        DEFAULT 0,\n    duration           INTEGER NOT NULL        DEFAULT 30,\n    device_no          INTEGER NOT NULL        DEFAULT 3,\n    traffic_multiplier REAL    NOT NULL        DEFAULT 0,\n    reset_cycle        TEXT    NOT NULL        DEFAULT '',\n    reset_interval     INTEGER NOT NULL        DEFAULT 0,\n    create_time        TIMESTAMP               DEFAULT CURRENT_TIMESTAMP,\n    update_time        TIMESTAMP               DEFAULT CURRENT_TIMESTAMP\n);\nALTER TABLE account\n    ADD COLUMN plan_id INTEGER NOT NULL DEFAULT 0;\nCREATE INDEX IF NOT EXISTS account_plan_id_index ON account (plan_id);\nALTER TABLE account\n    ADD COLUMN reset_cycle TEXT NOT NULL DEFAULT '';\nALTER TABLE account\n    ADD COLUMN reset_interval INTEGER NOT NULL DEFAULT 0;\nALTER TABLE account\n    ADD COLUMN reset_anchor INTEGER NOT NULL DEFAULT 0;\nALTER TABLE account\n    ADD COLUMN reset_at INTEGER NOT NULL DEFAULT 0;\nCREATE INDEX IF NOT EXISTS account_reset_cycle_index ON account (reset_cycle);\nALTER TABLE account\n    ADD COLUMN trashed_at INTEGER NOT NULL DEFAULT 0;\nCREATE INDEX IF NOT EXISTS account_trashed_at_index ON account (trashed_at);\nINSERT INTO config (key, value, remark)\nSELECT 'ACCOUNT_TRASH_RETENTION', '30', 'Account Trash Retention Days'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'ACCOUNT_TRASH_RETENTION');\nCREATE TABLE IF NOT EXISTS account_tag\n(\n    id          INTEGER PRIMARY KEY AUTOINCREMENT,\n    account_id  INTEGER NOT NULL DEFAULT 0,\n    tag         TEXT    NOT NULL DEFAULT '',\n    create_time TIMESTAMP        DEFAULT CURRENT_TIMESTAMP,\n    update_time TIMESTAMP        DEFAULT CURRENT_TIMESTAMP\n);\nCREATE UNIQUE INDEX IF NOT EXISTS account_tag_unique_index ON account_tag (account_id, tag);\nCREATE INDEX IF NOT EXISTS account_tag_tag_index ON account_tag (tag);\nCREATE TABLE IF NOT EXISTS account_alert\n(\n    id          INTEGER PRIMARY KEY AUTOINCREMENT,\n    account_id  INTEGER NOT NULL DEFAULT 0,\n    kind        TEXT    NOT NULL DEFAULT '',\n    threshold   INTEGER NOT NULL DEFAULT 0,\n    create_time TIMESTAMP        DEFAULT CURRENT_TIMESTAMP,\n    update_time TIMESTAMP        DEFAULT CURRENT_TIMESTAMP\n);\nCREATE UNIQUE INDEX IF NOT EXISTS account_alert_unique_index ON account_alert (account_id, kind, threshold);\nINSERT INTO config (key, value, remark)\nSELECT 'TELEGRAM_ALERT_JOB_ENABLE', '0', 'TELEGRAM Quota And Expiry Alert'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'TELEGRAM_ALERT_JOB_ENABLE');\nINSERT INTO config (key, value, remark)\nSELECT 'TELEGRAM_ALERT_QUOTA_THRESHOLD', '80,95,100', 'TELEGRAM Quota Alert Thresholds In Percent'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'TELEGRAM_ALERT_QUOTA_THRESHOLD');\nINSERT INTO config (key, value, remark)\nSELECT 'TELEGRAM_ALERT_EXPIRE_THRESHOLD', '7,3,1', 'TELEGRAM Expiry Alert Thresholds In Days'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'TELEGRAM_ALERT_EXPIRE_THRESHOLD');\nINSERT INTO config (key, value, remark)\nSELECT 'HYSTERIA2_STOP_TIMEOUT', '10', 'Hysteria2 Graceful Stop Timeout Seconds'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'HYSTERIA2_STOP_TIMEOUT');\nCREATE TABLE IF NOT EXISTS hysteria2_config_revision\n(\n    id          INTEGER PRIMARY KEY AUTOINCREMENT,\n    config      TEXT    NOT NULL DEFAULT '',\n    diff        TEXT    NOT NULL DEFAULT '',\n    operator    TEXT    NOT NULL DEFAULT '',\n    remark      TEXT    NOT NULL DEFAULT '',\n    create_time TIMESTAMP        DEFAULT CURRENT_TIMESTAMP,\n    update_time TIMESTAMP        DEFAULT CURRENT_TIMESTAMP\n);\nINSERT INTO config (key, value, remark)\nSELECT 'HYSTERIA2_START_TIMEOUT', '10', 'Hysteria2 Start Health Check Timeout Seconds'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'HYSTERIA2_START_TIMEOUT');\nINSERT INTO config (key, value, remark)\nSELECT 'RELEASE_GITHUB_TOKEN', '', 'Release GitHub Token'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'RELEASE_GITHUB_TOKEN');\nINSERT INTO config (key, value, remark)\nSELECT 'RELEASE_GITHUB_URL', '', 'Release GitHub Enterprise URL'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'RELEASE_GITHUB_URL');\nINSERT INTO config (key, value, remark)\nSELECT 'RELEASE_MIRROR_URL', '', 'Release Asset Mirror URL Template'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'RELEASE_MIRROR_URL');\nINSERT INTO config (key, value, remark)\nSELECT 'RELEASE_CACHE_TTL', '60', 'Release Cache TTL Minutes'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'RELEASE_CACHE_TTL');\nCREATE TABLE IF NOT EXISTS inbound\n(\n    id           INTEGER PRIMARY KEY AUTOINCREMENT,\n    name         TEXT    NOT NULL UNIQUE DEFAULT '',\n    config       TEXT    NOT NULL        DEFAULT '',\n    port_hopping TEXT    NOT NULL        DEFAULT '',\n    enable       INTEGER NOT NULL        DEFAULT 0,\n    create_time  TIMESTAMP               DEFAULT CURRENT_TIMESTAMP,\n    update_time  TIMESTAMP               DEFAULT CURRENT_TIMESTAMP\n);\nINSERT INTO config (key, value, remark)\nSELECT 'METRICS_TOKEN', '', 'Prometheus Metrics Token, Empty Disables /metrics'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'METRICS_TOKEN');\nINSERT INTO config (key, value, remark)\nSELECT 'METRICS_ACCOUNT_ENABLE', '0', 'Prometheus Metrics Per Account Labels'\n    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'METRICS_ACCOUNT_ENABLE');\nCREATE TABLE IF NOT EXISTS system_metrics\n(\n    id           INTEGER PRIMARY KEY AUTOINCREMENT,\n    period       TEXT    NOT NULL DEFAULT 'minute',\n    period_time  INTEGER NOT NULL DEFAULT 0,\n    samples      INTEGER NOT NULL DEFAULT 0,\n    cpu_percent  REAL    NOT NULL DEFAULT 0,\n    mem_percent  REAL    NOT NULL DEFAULT 0,\n    disk_percent REAL    NOT NULL DEFAULT 0,\n    load1        REAL    NOT NULL DEFAULT 0,\n    load5        REAL    NOT NULL DEFAULT 0,\n    load15       REAL    NOT NULL DEFAULT 0,\n    net_recv     REAL    NOT NULL DEFAULT 0,\n    net_sent     REAL    NOT NULL DEFAULT 0,\n    create_time  TIMESTAMP        DEFAULT CURRENT_TIMESTAMP,\n    update_time  TIMESTAMP        DEFAULT CURRENT_TIMESTAMP\n);\nCREATE UNIQUE INDEX IF NOT EXISTS system_metrics_unique_index ON system_metrics (period, period_time);\nALTER TABLE account\n    ADD COLUMN traffic_multiplier REAL NOT NULL DEFAULT 0;\nALTER TABLE account\n    ADD COLUMN billing_mode TEXT NOT NULL DEFAULT 'sum'"
 
 var sqliteDB *gorm.DB
 
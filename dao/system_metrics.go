@@ -0,0 +1,52 @@
+package dao
+
+import (
+	"errors"
+	"github.com/sirupsen/logrus"
+	"h-ui/model/constant"
+	"h-ui/model/entity"
+)
+
+// UpsertSystemMetrics average the sample into the bucket
+func UpsertSystemMetrics(period string, periodTime int64, sample entity.SystemMetrics) error {
+	if tx := sqliteDB.Exec("INSERT INTO system_metrics (period, period_time, samples, cpu_percent, mem_percent, disk_percent, "+
+		"load1, load5, load15, net_recv, net_sent) VALUES (?, ?, 1, ?, ?, ?, ?, ?, ?, ?, ?) "+
+		"ON CONFLICT (period, period_time) DO UPDATE SET "+
+		"cpu_percent = (cpu_percent * samples + excluded.cpu_percent) / (samples + 1), "+
+		"mem_percent = (mem_percent * samples + excluded.mem_percent) / (samples + 1), "+
+		"disk_percent = (disk_percent * samples + excluded.disk_percent) / (samples + 1), "+
+		"load1 = (load1 * samples + excluded.load1) / (samples + 1), "+
+		"load5 = (load5 * samples + excluded.load5) / (samples + 1), "+
+		"load15 = (load15 * samples + excluded.load15) / (samples + 1), "+
+		"net_recv = (net_recv * samples + excluded.net_recv) / (samples + 1), "+
+		"net_sent = (net_sent * samples + excluded.net_sent) / (samples + 1), "+
+		"samples = samples + 1, update_time = CURRENT_TIMESTAMP",
+		period, periodTime,
+		*sample.CpuPercent, *sample.MemPercent, *sample.DiskPercent,
+		*sample.Load1, *sample.Load5, *sample.Load15,
+		*sample.NetRecv, *sample.NetSent); tx.Error != nil {
+		logrus.Errorf("%v", tx.Error)
+		return errors.New(constant.SysError)
+	}
+	return nil
+}
+
+func ListSystemMetrics(query interface{}, args ...interface{}) ([]entity.SystemMetrics, error) {
+	var systemMetrics []entity.SystemMetrics
+	if tx := sqliteDB.Model(&entity.SystemMetrics{}).
+		Where(query, args...).
+		Order("period_time").
+		Find(&systemMetrics); tx.Error != nil {
+		logrus.Errorf("%v", tx.Error)
+		return systemMetrics, errors.New(constant.SysError)
+	}
+	return systemMetrics, nil
+}
+
+func DeleteSystemMetrics(query interface{}, args ...interface{}) error {
+	if tx := sqliteDB.Where(query, args...).Delete(&entity.SystemMetrics{}); tx.Error != nil {
+		logrus.Errorf("%v", tx.Error)
+		return errors.New(constant.SysError)
+	}
+	return nil
+}
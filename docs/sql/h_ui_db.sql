@@ -86,4 +86,146 @@ SELECT 'TELEGRAM_LOGIN_JOB_TEXT', '[time], [username] logged into the panel, IP
     WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'TELEGRAM_LOGIN_JOB_TEXT');
 INSERT INTO config (key, value, remark)
 SELECT 'CLASH_EXTENSION', '', 'Clash Subscription Extension'
-    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'CLASH_EXTENSION');
\ No newline at end of file
+    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'CLASH_EXTENSION');
+CREATE TABLE IF NOT EXISTS account_traffic
+(
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    account_id  INTEGER NOT NULL DEFAULT 0,
+    period      TEXT    NOT NULL DEFAULT 'hour',
+    period_time INTEGER NOT NULL DEFAULT 0,
+    download    INTEGER NOT NULL DEFAULT 0,
+    upload      INTEGER NOT NULL DEFAULT 0,
+    create_time TIMESTAMP        DEFAULT CURRENT_TIMESTAMP,
+    update_time TIMESTAMP        DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS account_traffic_unique_index ON account_traffic (account_id, period, period_time);
+CREATE INDEX IF NOT EXISTS account_traffic_period_time_index ON account_traffic (period, period_time);
+INSERT INTO config (key, value, remark)
+SELECT 'ACCOUNT_TRAFFIC_RETENTION', '90', 'Account Traffic History Retention Days'
+    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'ACCOUNT_TRAFFIC_RETENTION');
+CREATE TABLE IF NOT EXISTS plan
+(
+    id                 INTEGER PRIMARY KEY AUTOINCREMENT,
+    name               TEXT    NOT NULL UNIQUE DEFAULT '',
+    quota              INTEGER NOT NULL        DEFAULT 0,
+    duration           INTEGER NOT NULL        DEFAULT 30,
+    device_no          INTEGER NOT NULL        DEFAULT 3,
+    traffic_multiplier REAL    NOT NULL        DEFAULT 0,
+    reset_cycle        TEXT    NOT NULL        DEFAULT '',
+    reset_interval     INTEGER NOT NULL        DEFAULT 0,
+    create_time        TIMESTAMP               DEFAULT CURRENT_TIMESTAMP,
+    update_time        TIMESTAMP               DEFAULT CURRENT_TIMESTAMP
+);
+ALTER TABLE account
+    ADD COLUMN plan_id INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS account_plan_id_index ON account (plan_id);
+ALTER TABLE account
+    ADD COLUMN reset_cycle TEXT NOT NULL DEFAULT '';
+ALTER TABLE account
+    ADD COLUMN reset_interval INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE account
+    ADD COLUMN reset_anchor INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE account
+    ADD COLUMN reset_at INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS account_reset_cycle_index ON account (reset_cycle);
+ALTER TABLE account
+    ADD COLUMN trashed_at INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS account_trashed_at_index ON account (trashed_at);
+INSERT INTO config (key, value, remark)
+SELECT 'ACCOUNT_TRASH_RETENTION', '30', 'Account Trash Retention Days'
+    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'ACCOUNT_TRASH_RETENTION');
+CREATE TABLE IF NOT EXISTS account_tag
+(
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    account_id  INTEGER NOT NULL DEFAULT 0,
+    tag         TEXT    NOT NULL DEFAULT '',
+    create_time TIMESTAMP        DEFAULT CURRENT_TIMESTAMP,
+    update_time TIMESTAMP        DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS account_tag_unique_index ON account_tag (account_id, tag);
+CREATE INDEX IF NOT EXISTS account_tag_tag_index ON account_tag (tag);
+CREATE TABLE IF NOT EXISTS account_alert
+(
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    account_id  INTEGER NOT NULL DEFAULT 0,
+    kind        TEXT    NOT NULL DEFAULT '',
+    threshold   INTEGER NOT NULL DEFAULT 0,
+    create_time TIMESTAMP        DEFAULT CURRENT_TIMESTAMP,
+    update_time TIMESTAMP        DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS account_alert_unique_index ON account_alert (account_id, kind, threshold);
+INSERT INTO config (key, value, remark)
+SELECT 'TELEGRAM_ALERT_JOB_ENABLE', '0', 'TELEGRAM Quota And Expiry Alert'
+    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'TELEGRAM_ALERT_JOB_ENABLE');
+INSERT INTO config (key, value, remark)
+SELECT 'TELEGRAM_ALERT_QUOTA_THRESHOLD', '80,95,100', 'TELEGRAM Quota Alert Thresholds In Percent'
+    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'TELEGRAM_ALERT_QUOTA_THRESHOLD');
+INSERT INTO config (key, value, remark)
+SELECT 'TELEGRAM_ALERT_EXPIRE_THRESHOLD', '7,3,1', 'TELEGRAM Expiry Alert Thresholds In Days'
+    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'TELEGRAM_ALERT_EXPIRE_THRESHOLD');
+INSERT INTO config (key, value, remark)
+SELECT 'HYSTERIA2_STOP_TIMEOUT', '10', 'Hysteria2 Graceful Stop Timeout Seconds'
+    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'HYSTERIA2_STOP_TIMEOUT');
+CREATE TABLE IF NOT EXISTS hysteria2_config_revision
+(
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    config      TEXT    NOT NULL DEFAULT '',
+    diff        TEXT    NOT NULL DEFAULT '',
+    operator    TEXT    NOT NULL DEFAULT '',
+    remark      TEXT    NOT NULL DEFAULT '',
+    create_time TIMESTAMP        DEFAULT CURRENT_TIMESTAMP,
+    update_time TIMESTAMP        DEFAULT CURRENT_TIMESTAMP
+);
+INSERT INTO config (key, value, remark)
+SELECT 'HYSTERIA2_START_TIMEOUT', '10', 'Hysteria2 Start Health Check Timeout Seconds'
+    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'HYSTERIA2_START_TIMEOUT');
+INSERT INTO config (key, value, remark)
+SELECT 'RELEASE_GITHUB_TOKEN', '', 'Release GitHub Token'
+    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'RELEASE_GITHUB_TOKEN');
+INSERT INTO config (key, value, remark)
+SELECT 'RELEASE_GITHUB_URL', '', 'Release GitHub Enterprise URL'
+    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'RELEASE_GITHUB_URL');
+INSERT INTO config (key, value, remark)
+SELECT 'RELEASE_MIRROR_URL', '', 'Release Asset Mirror URL Template'
+    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'RELEASE_MIRROR_URL');
+INSERT INTO config (key, value, remark)
+SELECT 'RELEASE_CACHE_TTL', '60', 'Release Cache TTL Minutes'
+    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'RELEASE_CACHE_TTL');
+CREATE TABLE IF NOT EXISTS inbound
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    name         TEXT    NOT NULL UNIQUE DEFAULT '',
+    config       TEXT    NOT NULL        DEFAULT '',
+    port_hopping TEXT    NOT NULL        DEFAULT '',
+    enable       INTEGER NOT NULL        DEFAULT 0,
+    create_time  TIMESTAMP               DEFAULT CURRENT_TIMESTAMP,
+    update_time  TIMESTAMP               DEFAULT CURRENT_TIMESTAMP
+);
+INSERT INTO config (key, value, remark)
+SELECT 'METRICS_TOKEN', '', 'Prometheus Metrics Token, Empty Disables /metrics'
+    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'METRICS_TOKEN');
+INSERT INTO config (key, value, remark)
+SELECT 'METRICS_ACCOUNT_ENABLE', '0', 'Prometheus Metrics Per Account Labels'
+    WHERE NOT EXISTS (SELECT 1 FROM config WHERE key = 'METRICS_ACCOUNT_ENABLE');
+CREATE TABLE IF NOT EXISTS system_metrics
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    period       TEXT    NOT NULL DEFAULT 'minute',
+    period_time  INTEGER NOT NULL DEFAULT 0,
+    samples      INTEGER NOT NULL DEFAULT 0,
+    cpu_percent  REAL    NOT NULL DEFAULT 0,
+    mem_percent  REAL    NOT NULL DEFAULT 0,
+    disk_percent REAL    NOT NULL DEFAULT 0,
+    load1        REAL    NOT NULL DEFAULT 0,
+    load5        REAL    NOT NULL DEFAULT 0,
+    load15       REAL    NOT NULL DEFAULT 0,
+    net_recv     REAL    NOT NULL DEFAULT 0,
+    net_sent     REAL    NOT NULL DEFAULT 0,
+    create_time  TIMESTAMP        DEFAULT CURRENT_TIMESTAMP,
+    update_time  TIMESTAMP        DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS system_metrics_unique_index ON system_metrics (period, period_time);
+ALTER TABLE account
+    ADD COLUMN traffic_multiplier REAL NOT NULL DEFAULT 0;
+ALTER TABLE account
+    ADD COLUMN billing_mode TEXT NOT NULL DEFAULT 'sum';
\ No newline at end of file
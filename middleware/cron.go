@@ -12,12 +12,38 @@ import (
 
 func InitCron() error {
 	loc := time.Now().Location()
-	c := cron.New(cron.WithLocation(loc))
+	// a panicking job must not take the panel down
+	c := cron.New(cron.WithLocation(loc), cron.WithChain(cron.Recover(cron.DefaultLogger)))
 	_, err := c.AddFunc("@every 30s", service.CronHandleAccount)
 	if err != nil {
 		logrus.Errorf("cron add func CronHandleAccount err: %v", err)
 		return errors.New("cron add func CronHandleAccount err")
 	}
+	_, err = c.AddFunc("@daily", service.CronPurgeAccountTraffic)
+	if err != nil {
+		logrus.Errorf("cron add func CronPurgeAccountTraffic err: %v", err)
+		return errors.New("cron add func CronPurgeAccountTraffic err")
+	}
+	_, err = c.AddFunc("@daily", service.CronPurgeAccountTrash)
+	if err != nil {
+		logrus.Errorf("cron add func CronPurgeAccountTrash err: %v", err)
+		return errors.New("cron add func CronPurgeAccountTrash err")
+	}
+	_, err = c.AddFunc("@every 10m", service.CronResetTrafficCycle)
+	if err != nil {
+		logrus.Errorf("cron add func CronResetTrafficCycle err: %v", err)
+		return errors.New("cron add func CronResetTrafficCycle err")
+	}
+	_, err = c.AddFunc("@every 10s", service.CronSampleHysteria2Metrics)
+	if err != nil {
+		logrus.Errorf("cron add func CronSampleHysteria2Metrics err: %v", err)
+		return errors.New("cron add func CronSampleHysteria2Metrics err")
+	}
+	_, err = c.AddFunc("@every 1m", service.CronSampleSystemMetrics)
+	if err != nil {
+		logrus.Errorf("cron add func CronSampleSystemMetrics err: %v", err)
+		return errors.New("cron add func CronSampleSystemMetrics err")
+	}
 	resetTrafficCron, err := dao.GetConfig("key = ?", constant.ResetTrafficCron)
 	if err != nil {
 		return err
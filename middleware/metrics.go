@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"h-ui/model/constant"
+	"h-ui/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MetricsHandler the metrics are protected by METRICS_TOKEN instead of the jwt, an empty token disables them
+func MetricsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		config, err := service.GetConfig(constant.MetricsToken)
+		if err != nil || config.Value == nil || *config.Value == "" {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		token, found := strings.CutPrefix(c.Request.Header.Get("Authorization"), "Bearer ")
+		if !found || subtle.ConstantTimeCompare([]byte(token), []byte(*config.Value)) != 1 {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Next()
+	}
+}
@@ -26,4 +26,26 @@ type AccountExport struct {
 	UpdateTime   time.Time `json:"updateTime"`
 	LoginAt      int64     `json:"loginAt"`
 	ConAt        int64     `json:"conAt"`
+	PlanId       int64     `json:"planId"`
+
+	ResetCycle    string `json:"resetCycle"`
+	ResetInterval int64  `json:"resetInterval"`
+	ResetAnchor   int64  `json:"resetAnchor"`
+	ResetAt       int64  `json:"resetAt"`
+
+	TrafficMultiplier float64 `json:"trafficMultiplier"`
+	BillingMode       string  `json:"billingMode"`
+}
+
+type AccountGenerate struct {
+	Username     string
+	Pass         string
+	ConPass      string
+	SubscribeUrl string
+	Hysteria2Url string
+}
+
+type AccountTagCount struct {
+	Tag   string
+	Count int64
 }
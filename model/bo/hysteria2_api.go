@@ -4,3 +4,20 @@ type Hysteria2UserTraffic struct {
 	Tx int64 `json:"tx"` // upload
 	Rx int64 `json:"rx"` // download
 }
+
+type ProcessStats struct {
+	RestartCount  int64 // automatic restart attempts after a crash
+	LastExitCode  int
+	LastCrashTime int64
+}
+
+// ProcessMetrics a sample of the resource usage of a process
+type ProcessMetrics struct {
+	Time       int64   // sample time in milliseconds
+	CpuPercent float64 // of one core, above 100 when several cores are busy
+	Rss        uint64  // bytes
+	Fds        int32   // open file descriptors
+	Threads    int32
+	Uptime     int64  // seconds
+	UdpDrops   uint64 // datagrams dropped by the udp sockets of the process since they were opened
+}
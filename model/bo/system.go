@@ -0,0 +1,26 @@
+package bo
+
+// SystemMetrics a sample of the system usage
+type SystemMetrics struct {
+	Time        int64   // sample time in milliseconds
+	CpuPercent  float64 // of every core together, measured since the last sample
+	MemPercent  float64
+	DiskPercent float64
+	Load1       float64
+	Load5       float64
+	Load15      float64
+	NetRecv     uint64 // bytes received by every interface except loopback since boot
+	NetSent     uint64 // bytes sent by every interface except loopback since boot
+
+	Interfaces      map[string]InterfaceCounters // by interface name, counted since boot
+	TcpSockets      int64
+	UdpSockets      int64
+	UdpRcvbufErrors uint64 // udp datagrams dropped since boot because a receive buffer was full
+}
+
+type InterfaceCounters struct {
+	BytesRecv   uint64
+	BytesSent   uint64
+	PacketsRecv uint64
+	PacketsSent uint64
+}
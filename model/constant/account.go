@@ -0,0 +1,54 @@
+package constant
+
+const (
+	TrafficPeriodHour = "hour"
+	TrafficPeriodDay  = "day"
+)
+
+const (
+	RankMetricUpload   = "upload"
+	RankMetricDownload = "download"
+	RankMetricTotal    = "total"
+)
+
+const (
+	RankPeriodToday  = "today"
+	RankPeriodWeek   = "7d"
+	RankPeriodCycle  = "cycle" // every account since its last reset
+	RankPeriodCustom = "custom"
+)
+
+const (
+	BillingModeSum      = "sum"
+	BillingModeDownload = "download"
+	BillingModeUpload   = "upload"
+	BillingModeMax      = "max" // the larger of download and upload
+)
+
+const (
+	ResetCycleGlobal  = "" // follow RESET_TRAFFIC_CRON
+	ResetCycleNever   = "never"
+	ResetCycleMonthly = "monthly"
+	ResetCycleDays    = "days"
+)
+
+const (
+	ImportActionCreate  = "create"
+	ImportActionUpdate  = "update"
+	ImportActionSkip    = "skip"
+	ImportActionInvalid = "invalid"
+)
+
+const (
+	TagActionKick         = "kick"
+	TagActionResetTraffic = "resetTraffic"
+	TagActionExtendExpire = "extendExpire"
+	TagActionDeviceNo     = "deviceNo"
+	TagActionDisable      = "disable"
+	TagActionEnable       = "enable"
+)
+
+const (
+	AlertKindQuota  = "quota"
+	AlertKindExpire = "expire"
+)
@@ -1,24 +1,37 @@
 package constant
 
 const (
-	HUIWebPort                 = "H_UI_WEB_PORT"
-	HUIWebContext              = "H_UI_WEB_CONTEXT"
-	HUICrtPath                 = "H_UI_CRT_PATH"
-	HUIKeyPath                 = "H_UI_KEY_PATH"
-	JwtSecret                  = "JWT_SECRET"
-	Hysteria2Enable            = "HYSTERIA2_ENABLE"
-	Hysteria2Config            = "HYSTERIA2_CONFIG"
-	Hysteria2TrafficTime       = "HYSTERIA2_TRAFFIC_TIME"
-	Hysteria2ConfigRemark      = "HYSTERIA2_CONFIG_REMARK"
-	Hysteria2ConfigPortHopping = "HYSTERIA2_CONFIG_PORT_HOPPING"
-	ResetTrafficCron           = "RESET_TRAFFIC_CRON"
-	TelegramEnable             = "TELEGRAM_ENABLE"
-	TelegramToken              = "TELEGRAM_TOKEN"
-	TelegramChatId             = "TELEGRAM_CHAT_ID"
-	TelegramDebug              = "TELEGRAM_DEBUG"
-	TelegramLoginJobEnable     = "TELEGRAM_LOGIN_JOB_ENABLE"
-	TelegramLoginJobText       = "TELEGRAM_LOGIN_JOB_TEXT"
-	ClashExtension             = "CLASH_EXTENSION"
-	HUIAllowedDomain           = "HUI_ALLOWED_DOMAIN"
-	HUISecurityPath            = "HUI_SECURITY_PATH"
+	HUIWebPort                   = "H_UI_WEB_PORT"
+	HUIWebContext                = "H_UI_WEB_CONTEXT"
+	HUICrtPath                   = "H_UI_CRT_PATH"
+	HUIKeyPath                   = "H_UI_KEY_PATH"
+	JwtSecret                    = "JWT_SECRET"
+	Hysteria2Enable              = "HYSTERIA2_ENABLE"
+	Hysteria2Config              = "HYSTERIA2_CONFIG"
+	Hysteria2TrafficTime         = "HYSTERIA2_TRAFFIC_TIME"
+	Hysteria2ConfigRemark        = "HYSTERIA2_CONFIG_REMARK"
+	Hysteria2ConfigPortHopping   = "HYSTERIA2_CONFIG_PORT_HOPPING"
+	Hysteria2StopTimeout         = "HYSTERIA2_STOP_TIMEOUT"
+	Hysteria2StartTimeout        = "HYSTERIA2_START_TIMEOUT"
+	ReleaseGithubToken           = "RELEASE_GITHUB_TOKEN"
+	ReleaseGithubUrl             = "RELEASE_GITHUB_URL"
+	ReleaseMirrorUrl             = "RELEASE_MIRROR_URL"
+	ReleaseCacheTtl              = "RELEASE_CACHE_TTL"
+	MetricsToken                 = "METRICS_TOKEN"
+	MetricsAccountEnable         = "METRICS_ACCOUNT_ENABLE"
+	ResetTrafficCron             = "RESET_TRAFFIC_CRON"
+	TelegramEnable               = "TELEGRAM_ENABLE"
+	TelegramToken                = "TELEGRAM_TOKEN"
+	TelegramChatId               = "TELEGRAM_CHAT_ID"
+	TelegramDebug                = "TELEGRAM_DEBUG"
+	TelegramLoginJobEnable       = "TELEGRAM_LOGIN_JOB_ENABLE"
+	TelegramLoginJobText         = "TELEGRAM_LOGIN_JOB_TEXT"
+	TelegramAlertJobEnable       = "TELEGRAM_ALERT_JOB_ENABLE"
+	TelegramAlertQuotaThreshold  = "TELEGRAM_ALERT_QUOTA_THRESHOLD"
+	TelegramAlertExpireThreshold = "TELEGRAM_ALERT_EXPIRE_THRESHOLD"
+	ClashExtension               = "CLASH_EXTENSION"
+	HUIAllowedDomain             = "HUI_ALLOWED_DOMAIN"
+	HUISecurityPath              = "HUI_SECURITY_PATH"
+	AccountTrafficRetention      = "ACCOUNT_TRAFFIC_RETENTION"
+	AccountTrashRetention        = "ACCOUNT_TRASH_RETENTION"
 )
@@ -0,0 +1,13 @@
+package constant
+
+const (
+	SystemMetricsPeriodMinute = "minute"
+	SystemMetricsPeriodHour   = "hour"
+)
+
+const (
+	SystemMetricsWindowHour  = "1h"
+	SystemMetricsWindowDay   = "24h"
+	SystemMetricsWindowWeek  = "7d"
+	SystemMetricsWindowMonth = "30d"
+)
@@ -5,15 +5,27 @@ const (
 	SqliteDBDir   = "data/"
 	BinDir        = "bin/"
 	ExportPathDir = "export/"
+	// Hysteria2VersionDir every installed hysteria2 is kept at bin/versions/<version>/<bin name>
+	Hysteria2VersionDir = "bin/versions/"
+	// Hysteria2AclDir the acl files the configs may reference
+	Hysteria2AclDir = "bin/acl/"
 
 	SqliteDBPath = "data/h_ui.db"
 
 	Hysteria2ConfigPath = "bin/hysteria2.yaml"
+	// Hysteria2InboundConfigPath the config the inbound with the id is started with
+	Hysteria2InboundConfigPath = "bin/hysteria2-inbound-%d.yaml"
+	// Hysteria2PreviousVersionPath the version that was active before the last switch
+	Hysteria2PreviousVersionPath = "bin/versions/previous"
+	// ReleaseCachePath the releases listed from github
+	ReleaseCachePath = "bin/release_cache.json"
 
 	SystemLogPath    = "logs/h-ui.log"
 	Hysteria2LogPath = "logs/hysteria2.log"
 
 	TokenType = "Bearer"
 
+	DefaultPass = "sysadmin"
+
 	Version = "v0.0.18"
 )
@@ -4,6 +4,7 @@ type AccountPageDto struct {
 	BaseDto
 	Username *string `json:"username" form:"username" validate:"omitempty,min=1,max=32"`
 	Deleted  *int64  `json:"deleted" form:"deleted" validate:"omitempty,oneof=0 1"`
+	Tag      *string `json:"tag" form:"tag" validate:"omitempty,min=1,max=32"`
 }
 
 type LoginDto struct {
@@ -15,10 +16,17 @@ type AccountSaveDto struct {
 	Username   *string `json:"username" form:"username" validate:"required,min=6,max=32,validateStr"`
 	Pass       *string `json:"pass" form:"pass" validate:"required,min=6,max=32,validateStr"`
 	ConPass    *string `json:"conPass" form:"conPass" validate:"required,min=6,max=32,validateStr"`
-	Quota      *int64  `json:"quota" form:"quota" validate:"required,min=-1"`
-	ExpireTime *int64  `json:"expireTime" form:"expireTime" validate:"required,min=0"`
-	DeviceNo   *int64  `json:"deviceNo" form:"deviceNo" validate:"required,min=1"`
+	Quota      *int64  `json:"quota" form:"quota" validate:"required_without=PlanId,omitempty,min=-1"`
+	ExpireTime *int64  `json:"expireTime" form:"expireTime" validate:"required_without=PlanId,omitempty,min=0"`
+	DeviceNo   *int64  `json:"deviceNo" form:"deviceNo" validate:"required_without=PlanId,omitempty,min=1"`
 	Deleted    *int64  `json:"deleted" form:"deleted" validate:"required,oneof=0 1"`
+	PlanId     *int64  `json:"planId" form:"planId" validate:"omitempty,gt=0"` // fills the fields that are not given from the plan
+
+	ResetCycle    *string `json:"resetCycle" form:"resetCycle" validate:"omitempty,oneof='' never monthly days"`
+	ResetInterval *int64  `json:"resetInterval" form:"resetInterval" validate:"required_if=ResetCycle days,omitempty,min=1"`
+
+	TrafficMultiplier *float64 `json:"trafficMultiplier" form:"trafficMultiplier" validate:"omitempty,min=0"` // 0 follows the plan
+	BillingMode       *string  `json:"billingMode" form:"billingMode" validate:"omitempty,oneof=sum download upload max"`
 }
 
 type AccountUpdateDto struct {
@@ -30,4 +38,76 @@ type AccountUpdateDto struct {
 	ExpireTime *int64  `json:"expireTime" form:"expireTime" validate:"omitempty,min=0"`
 	DeviceNo   *int64  `json:"deviceNo" form:"deviceNo" validate:"omitempty,min=1"`
 	Deleted    *int64  `json:"deleted" form:"deleted" validate:"omitempty,oneof=0 1"`
+
+	ResetCycle    *string `json:"resetCycle" form:"resetCycle" validate:"omitempty,oneof='' never monthly days"`
+	ResetInterval *int64  `json:"resetInterval" form:"resetInterval" validate:"omitempty,min=1"`
+
+	TrafficMultiplier *float64 `json:"trafficMultiplier" form:"trafficMultiplier" validate:"omitempty,min=0"` // 0 follows the plan
+	BillingMode       *string  `json:"billingMode" form:"billingMode" validate:"omitempty,oneof=sum download upload max"`
+}
+
+type UserConPassUpdateDto struct {
+	ConPass *string `json:"conPass" form:"conPass" validate:"required,min=6,max=32,validateStr"`
+}
+
+type AccountTrafficDto struct {
+	AccountId *int64  `json:"accountId" form:"accountId" validate:"omitempty,gt=0"`
+	Period    *string `json:"period" form:"period" validate:"required,oneof=hour day"`
+	StartTime *int64  `json:"startTime" form:"startTime" validate:"required,gt=0"`
+	EndTime   *int64  `json:"endTime" form:"endTime" validate:"required,gtfield=StartTime"`
+}
+
+type AccountRankDto struct {
+	Metric    *string `json:"metric" form:"metric" validate:"required,oneof=upload download total"`
+	Period    *string `json:"period" form:"period" validate:"required,oneof=today 7d cycle custom"`
+	StartTime *int64  `json:"startTime" form:"startTime" validate:"required_if=Period custom,omitempty,gt=0"`
+	EndTime   *int64  `json:"endTime" form:"endTime" validate:"required_if=Period custom,omitempty,gtfield=StartTime"`
+	Limit     *int64  `json:"limit" form:"limit" validate:"omitempty,min=1,max=1000"` // 10 by default
+}
+
+type AccountGenerateDto struct {
+	Pattern    *string `json:"pattern" form:"pattern" validate:"required,min=1,max=32"` // {n} is replaced by the sequence number, without it the pattern is a prefix
+	Count      *int64  `json:"count" form:"count" validate:"required,min=1,max=1000"`
+	Start      *int64  `json:"start" form:"start" validate:"omitempty,min=0"`
+	PlanId     *int64  `json:"planId" form:"planId" validate:"omitempty,gt=0"`
+	Quota      *int64  `json:"quota" form:"quota" validate:"required_without=PlanId,omitempty,min=-1"`
+	ExpireTime *int64  `json:"expireTime" form:"expireTime" validate:"required_without=PlanId,omitempty,min=0"`
+	DeviceNo   *int64  `json:"deviceNo" form:"deviceNo" validate:"required_without=PlanId,omitempty,min=1"`
+	Protocol   *string `json:"protocol" form:"protocol" validate:"required,min=1,max=8"`
+	Host       *string `json:"host" form:"host" validate:"required,min=1,max=301"`
+}
+
+// AccountImportDto one row of an account import, the fields that are not given are left unchanged
+type AccountImportDto struct {
+	Username      *string `json:"username" validate:"required,min=6,max=32,validateStr"`
+	Pass          *string `json:"pass" validate:"omitempty,min=6,max=32,validateStr"` // plain text or a hash from an export
+	ConPass       *string `json:"conPass" validate:"omitempty,min=6,max=32,validateStr"`
+	Quota         *int64  `json:"quota" validate:"omitempty,min=-1"`
+	Download      *int64  `json:"download" validate:"omitempty,min=0"`
+	Upload        *int64  `json:"upload" validate:"omitempty,min=0"`
+	ExpireTime    *int64  `json:"expireTime" validate:"omitempty,min=0"`
+	DeviceNo      *int64  `json:"deviceNo" validate:"omitempty,min=1"`
+	KickUtilTime  *int64  `json:"kickUtilTime" validate:"omitempty,min=0"`
+	Role          *string `json:"role" validate:"omitempty,oneof=admin user"`
+	Deleted       *int64  `json:"deleted" validate:"omitempty,oneof=0 1"`
+	PlanId        *int64  `json:"planId" validate:"omitempty,min=0"`
+	ResetCycle    *string `json:"resetCycle" validate:"omitempty,oneof='' never monthly days"`
+	ResetInterval *int64  `json:"resetInterval" validate:"omitempty,min=1"`
+
+	TrafficMultiplier *float64 `json:"trafficMultiplier" validate:"omitempty,min=0"` // 0 follows the plan
+	BillingMode       *string  `json:"billingMode" validate:"omitempty,oneof=sum download upload max"`
+}
+
+type AccountTagDto struct {
+	Ids  []int64  `json:"ids" form:"ids" validate:"required,min=1"`
+	Tags []string `json:"tags" form:"tags" validate:"required,min=1,dive,min=1,max=32"`
+}
+
+// AccountTagActionDto apply the action to every account of the tag
+type AccountTagActionDto struct {
+	Tag          *string `json:"tag" form:"tag" validate:"required,min=1,max=32"`
+	Action       *string `json:"action" form:"action" validate:"required,oneof=kick resetTraffic extendExpire deviceNo disable enable"`
+	KickUtilTime *int64  `json:"kickUtilTime" form:"kickUtilTime" validate:"required_if=Action kick,omitempty,min=0"` // 解禁时间
+	Days         *int64  `json:"days" form:"days" validate:"required_if=Action extendExpire,omitempty,min=1"`
+	DeviceNo     *int64  `json:"deviceNo" form:"deviceNo" validate:"required_if=Action deviceNo,omitempty,min=1"`
 }
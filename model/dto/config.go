@@ -16,3 +16,8 @@ type ConfigUpdateDto struct {
 type ConfigsUpdateDto struct {
 	ConfigUpdateDtos []ConfigUpdateDto `json:"configUpdateDtos" form:"configUpdateDtos" validate:"required"`
 }
+
+type Hysteria2ConfigRevisionDiffDto struct {
+	FromId *int64 `json:"fromId" form:"fromId" validate:"required,gt=0"`
+	ToId   *int64 `json:"toId" form:"toId" validate:"required,gt=0"`
+}
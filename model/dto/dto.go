@@ -10,3 +10,7 @@ type BaseDto struct {
 type IdDto struct {
 	Id *int64 `json:"id" form:"id" validate:"required,gt=0"` // 主键
 }
+
+type IdsDto struct {
+	Ids []int64 `json:"ids" form:"ids" validate:"required,min=1"` // 主键
+}
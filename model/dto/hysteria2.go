@@ -12,7 +12,7 @@ type Hysteria2KickDto struct {
 }
 
 type Hysteria2VersionDto struct {
-	Version *string `json:"version" form:"version" validate:"required,min=1,max=10"`
+	Version *string `json:"version" form:"version" validate:"required,min=1,max=32"`
 }
 
 type Hysteria2SubscribeUrlDto struct {
@@ -24,4 +24,9 @@ type Hysteria2SubscribeUrlDto struct {
 type Hysteria2UrlDto struct {
 	AccountId *int64  `json:"accountId" form:"accountId" validate:"required,gt=0"`
 	Hostname  *string `json:"hostname" form:"hostname" validate:"required,min=1,max=255"`
+	InboundId *int64  `json:"inboundId" form:"inboundId" validate:"omitempty,min=0"` // 0 the hysteria2 configured by HYSTERIA2_CONFIG
+}
+
+type Hysteria2MetricsDto struct {
+	InboundId *int64 `json:"inboundId" form:"inboundId" validate:"omitempty,min=0"` // 0 the hysteria2 configured by HYSTERIA2_CONFIG
 }
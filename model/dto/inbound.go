@@ -0,0 +1,23 @@
+package dto
+
+import "h-ui/model/bo"
+
+type InboundPageDto struct {
+	BaseDto
+	Name *string `json:"name" form:"name" validate:"omitempty,min=1,max=32"`
+}
+
+type InboundSaveDto struct {
+	Name        *string                   `json:"name" form:"name" validate:"required,min=1,max=32"`
+	Config      *bo.Hysteria2ServerConfig `json:"config" form:"config" validate:"required"`
+	PortHopping *string                   `json:"portHopping" form:"portHopping" validate:"omitempty,max=128"`
+	Enable      *int64                    `json:"enable" form:"enable" validate:"omitempty,oneof=0 1"`
+}
+
+type InboundUpdateDto struct {
+	IdDto
+	Name        *string                   `json:"name" form:"name" validate:"omitempty,min=1,max=32"`
+	Config      *bo.Hysteria2ServerConfig `json:"config" form:"config" validate:"omitempty"`
+	PortHopping *string                   `json:"portHopping" form:"portHopping" validate:"omitempty,max=128"`
+	Enable      *int64                    `json:"enable" form:"enable" validate:"omitempty,oneof=0 1"`
+}
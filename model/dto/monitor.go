@@ -0,0 +1,5 @@
+package dto
+
+type SystemMetricsDto struct {
+	Window *string `json:"window" form:"window" validate:"required,oneof=1h 24h 7d 30d"`
+}
@@ -0,0 +1,37 @@
+package dto
+
+type PlanPageDto struct {
+	BaseDto
+	Name *string `json:"name" form:"name" validate:"omitempty,min=1,max=32"`
+}
+
+type PlanSaveDto struct {
+	Name              *string  `json:"name" form:"name" validate:"required,min=1,max=32"`
+	Quota             *int64   `json:"quota" form:"quota" validate:"required,min=-1"`
+	Duration          *int64   `json:"duration" form:"duration" validate:"required,min=1"`
+	DeviceNo          *int64   `json:"deviceNo" form:"deviceNo" validate:"required,min=1"`
+	TrafficMultiplier *float64 `json:"trafficMultiplier" form:"trafficMultiplier" validate:"omitempty,min=0"`
+	ResetCycle        *string  `json:"resetCycle" form:"resetCycle" validate:"omitempty,oneof='' never monthly days"`
+	ResetInterval     *int64   `json:"resetInterval" form:"resetInterval" validate:"required_if=ResetCycle days,omitempty,min=1"`
+}
+
+type PlanUpdateDto struct {
+	IdDto
+	Name              *string  `json:"name" form:"name" validate:"omitempty,min=1,max=32"`
+	Quota             *int64   `json:"quota" form:"quota" validate:"omitempty,min=-1"`
+	Duration          *int64   `json:"duration" form:"duration" validate:"omitempty,min=1"`
+	DeviceNo          *int64   `json:"deviceNo" form:"deviceNo" validate:"omitempty,min=1"`
+	TrafficMultiplier *float64 `json:"trafficMultiplier" form:"trafficMultiplier" validate:"omitempty,min=0"`
+	ResetCycle        *string  `json:"resetCycle" form:"resetCycle" validate:"omitempty,oneof='' never monthly days"`
+	ResetInterval     *int64   `json:"resetInterval" form:"resetInterval" validate:"omitempty,min=1"`
+	Propagate         *bool    `json:"propagate" form:"propagate" validate:"omitempty"` // apply to every account on the plan
+}
+
+type PlanAssignDto struct {
+	Ids    []int64 `json:"ids" form:"ids" validate:"required,min=1"`
+	PlanId *int64  `json:"planId" form:"planId" validate:"required,gt=0"`
+}
+
+type AccountRenewDto struct {
+	Ids []int64 `json:"ids" form:"ids" validate:"required,min=1"`
+}
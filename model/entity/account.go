@@ -16,4 +16,15 @@ type Account struct {
 
 	LoginAt *int64 `gorm:"column:login_at;default:0" json:"loginAt"`
 	ConAt   *int64 `gorm:"column:con_at;default:0" json:"conAt"`
+	PlanId  *int64 `gorm:"column:plan_id;default:0" json:"planId"`
+
+	ResetCycle    *string `gorm:"column:reset_cycle;default:''" json:"resetCycle"`
+	ResetInterval *int64  `gorm:"column:reset_interval;default:0" json:"resetInterval"` // days
+	ResetAnchor   *int64  `gorm:"column:reset_anchor;default:0" json:"resetAnchor"`     // activation time, 0 falls back to create_time
+	ResetAt       *int64  `gorm:"column:reset_at;default:0" json:"resetAt"`             // last cycle reset
+
+	TrashedAt *int64 `gorm:"column:trashed_at;default:0" json:"trashedAt"` // 0 not in the trash
+
+	TrafficMultiplier *float64 `gorm:"column:traffic_multiplier;default:0" json:"trafficMultiplier"` // 0 follows the plan
+	BillingMode       *string  `gorm:"column:billing_mode;default:'sum'" json:"billingMode"`         // the traffic counted against the quota
 }
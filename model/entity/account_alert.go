@@ -0,0 +1,9 @@
+package entity
+
+// AccountAlert a threshold alert that has been sent, it is removed when the account falls below the threshold again
+type AccountAlert struct {
+	AccountId  *int64  `gorm:"column:account_id;default:0" json:"accountId"`
+	Kind       *string `gorm:"column:kind;default:''" json:"kind"`
+	Threshold  *int64  `gorm:"column:threshold;default:0" json:"threshold"` // percent of the quota or days before the expiry
+	BaseEntity `gorm:"embedded"`
+}
@@ -0,0 +1,7 @@
+package entity
+
+type AccountTag struct {
+	AccountId  *int64  `gorm:"column:account_id;default:0" json:"accountId"`
+	Tag        *string `gorm:"column:tag;default:''" json:"tag"`
+	BaseEntity `gorm:"embedded"`
+}
@@ -0,0 +1,10 @@
+package entity
+
+type AccountTraffic struct {
+	AccountId  *int64  `gorm:"column:account_id;default:0" json:"accountId"`
+	Period     *string `gorm:"column:period;default:'hour'" json:"period"`
+	PeriodTime *int64  `gorm:"column:period_time;default:0" json:"periodTime"` // start of the bucket
+	Download   *int64  `gorm:"column:download;default:0" json:"download"`
+	Upload     *int64  `gorm:"column:upload;default:0" json:"upload"`
+	BaseEntity `gorm:"embedded"`
+}
@@ -0,0 +1,9 @@
+package entity
+
+type Hysteria2ConfigRevision struct {
+	Config     *string `gorm:"column:config;default:''" json:"config"`
+	Diff       *string `gorm:"column:diff;default:''" json:"diff"` // unified diff against the previous config
+	Operator   *string `gorm:"column:operator;default:''" json:"operator"`
+	Remark     *string `gorm:"column:remark;default:''" json:"remark"`
+	BaseEntity `gorm:"embedded"`
+}
@@ -0,0 +1,10 @@
+package entity
+
+// Inbound an additional hysteria2 instance next to the one configured by HYSTERIA2_CONFIG
+type Inbound struct {
+	Name        *string `gorm:"column:name;default:''" json:"name"`
+	Config      *string `gorm:"column:config;default:''" json:"config"` // hysteria2 server config yaml
+	PortHopping *string `gorm:"column:port_hopping;default:''" json:"portHopping"`
+	Enable      *int64  `gorm:"column:enable;default:0" json:"enable"` // 1 started with the panel
+	BaseEntity  `gorm:"embedded"`
+}
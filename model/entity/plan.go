@@ -0,0 +1,12 @@
+package entity
+
+type Plan struct {
+	Name              *string  `gorm:"column:name;default:''" json:"name"`
+	Quota             *int64   `gorm:"column:quota;default:0" json:"quota"`
+	Duration          *int64   `gorm:"column:duration;default:30" json:"duration"` // days
+	DeviceNo          *int64   `gorm:"column:device_no;default:3" json:"deviceNo"`
+	TrafficMultiplier *float64 `gorm:"column:traffic_multiplier;default:0" json:"trafficMultiplier"` // 0 follows HYSTERIA2_TRAFFIC_TIME
+	ResetCycle        *string  `gorm:"column:reset_cycle;default:''" json:"resetCycle"`
+	ResetInterval     *int64   `gorm:"column:reset_interval;default:0" json:"resetInterval"` // days
+	BaseEntity        `gorm:"embedded"`
+}
@@ -0,0 +1,17 @@
+package entity
+
+// SystemMetrics the system usage averaged over the bucket
+type SystemMetrics struct {
+	Period      *string  `gorm:"column:period;default:'minute'" json:"period"`
+	PeriodTime  *int64   `gorm:"column:period_time;default:0" json:"periodTime"` // start of the bucket
+	Samples     *int64   `gorm:"column:samples;default:0" json:"samples"`        // samples averaged into the bucket
+	CpuPercent  *float64 `gorm:"column:cpu_percent;default:0" json:"cpuPercent"`
+	MemPercent  *float64 `gorm:"column:mem_percent;default:0" json:"memPercent"`
+	DiskPercent *float64 `gorm:"column:disk_percent;default:0" json:"diskPercent"`
+	Load1       *float64 `gorm:"column:load1;default:0" json:"load1"`
+	Load5       *float64 `gorm:"column:load5;default:0" json:"load5"`
+	Load15      *float64 `gorm:"column:load15;default:0" json:"load15"`
+	NetRecv     *float64 `gorm:"column:net_recv;default:0" json:"netRecv"` // bytes/s
+	NetSent     *float64 `gorm:"column:net_sent;default:0" json:"netSent"` // bytes/s
+	BaseEntity  `gorm:"embedded"`
+}
@@ -17,6 +17,17 @@ type AccountVo struct {
 
 	LoginAt int64 `json:"loginAt"`
 	ConAt   int64 `json:"conAt"`
+	PlanId  int64 `json:"planId"`
+
+	ResetCycle    string `json:"resetCycle"`
+	ResetInterval int64  `json:"resetInterval"`
+	ResetAt       int64  `json:"resetAt"` // last cycle reset
+
+	TrashedAt int64    `json:"trashedAt"`
+	Tags      []string `json:"tags"`
+
+	TrafficMultiplier float64 `json:"trafficMultiplier"` // 0 follows the plan
+	BillingMode       string  `json:"billingMode"`
 }
 type AccountPageVo struct {
 	AccountVos []AccountVo `json:"records"`
@@ -28,3 +39,51 @@ type AccountInfoVo struct {
 	Username string   `json:"username"`
 	Roles    []string `json:"roles"`
 }
+
+type AccountTrafficVo struct {
+	Time     int64 `json:"time"` // start of the bucket
+	Download int64 `json:"download"`
+	Upload   int64 `json:"upload"`
+}
+
+type AccountImportRowVo struct {
+	Line     int      `json:"line"`
+	Username string   `json:"username"`
+	Action   string   `json:"action"` // create/update/skip/invalid
+	Errors   []string `json:"errors"`
+}
+
+type AccountImportVo struct {
+	Create  int64                `json:"create"`
+	Update  int64                `json:"update"`
+	Skip    int64                `json:"skip"`
+	Invalid int64                `json:"invalid"`
+	Rows    []AccountImportRowVo `json:"rows"`
+}
+
+type AccountTagVo struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"` // number of accounts
+}
+
+type AccountRankVo struct {
+	Rank          int64    `json:"rank"`
+	AccountId     int64    `json:"accountId"`
+	Username      string   `json:"username"`
+	Download      int64    `json:"download"`
+	Upload        int64    `json:"upload"`
+	Value         int64    `json:"value"`         // of the ranked metric
+	Share         float64  `json:"share"`         // percent of the metric summed over every account
+	PreviousValue int64    `json:"previousValue"` // of the ranked metric in the equally long period before
+	Change        *float64 `json:"change"`        // percent against the previous period, null when the previous value is 0
+}
+
+type AccountRankReportVo struct {
+	Metric        string          `json:"metric"`
+	Period        string          `json:"period"`
+	StartTime     int64           `json:"startTime"` // 0 for the cycle, every account has its own
+	EndTime       int64           `json:"endTime"`
+	Total         int64           `json:"total"` // the metric summed over every account
+	PreviousTotal int64           `json:"previousTotal"`
+	Accounts      []AccountRankVo `json:"accounts"`
+}
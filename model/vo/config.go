@@ -5,3 +5,27 @@ type ConfigVo struct {
 	Value  string `json:"value"`
 	Remark string `json:"remark"`
 }
+
+// FieldErrorVo a validation error addressed by the json path of the field, e.g. tls.cert or outbounds[0].name
+type FieldErrorVo struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type Hysteria2ConfigValidateVo struct {
+	Valid  bool           `json:"valid"`
+	Errors []FieldErrorVo `json:"errors"`
+}
+
+type Hysteria2ConfigRevisionVo struct {
+	BaseVo
+	Operator string `json:"operator"`
+	Remark   string `json:"remark"`
+	Config   string `json:"config,omitempty"`
+	Diff     string `json:"diff,omitempty"` // unified diff against the previous revision
+}
+
+type Hysteria2ConfigRevisionPageVo struct {
+	Hysteria2ConfigRevisionVos []Hysteria2ConfigRevisionVo `json:"records"`
+	Total                      int64                       `json:"total"`
+}
@@ -38,3 +38,9 @@ type Hysteria2AcmePathVo struct {
 	CrtPath string `json:"crtPath"`
 	KeyPath string `json:"keyPath"`
 }
+
+type Hysteria2VersionVo struct {
+	Version  string `json:"version"`
+	Active   bool   `json:"active"`   // the version of the binary hysteria2 is started with
+	Previous bool   `json:"previous"` // the version a rollback switches to
+}
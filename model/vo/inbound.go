@@ -0,0 +1,17 @@
+package vo
+
+import "h-ui/model/bo"
+
+type InboundVo struct {
+	BaseVo
+	Name        string                   `json:"name"`
+	Config      bo.Hysteria2ServerConfig `json:"config"`
+	PortHopping string                   `json:"portHopping"`
+	Enable      int64                    `json:"enable"`
+	Running     bool                     `json:"running"`
+}
+
+type InboundPageVo struct {
+	InboundVos []InboundVo `json:"records"`
+	Total      int64       `json:"total"`
+}
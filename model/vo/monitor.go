@@ -5,11 +5,79 @@ type SystemMonitorVo struct {
 	CpuPercent  float64 `json:"cpuPercent"`
 	MemPercent  float64 `json:"memPercent"`
 	DiskPercent float64 `json:"diskPercent"`
+	Load1       float64 `json:"load1"`
+	Load5       float64 `json:"load5"`
+	Load15      float64 `json:"load15"`
+	NetRecv     float64 `json:"netRecv"`    // bytes/s
+	NetSent     float64 `json:"netSent"`    // bytes/s
+	SampleTime  int64   `json:"sampleTime"` // milliseconds
+
+	Interfaces      []InterfaceMonitorVo `json:"interfaces"` // the interfaces detected for port hopping
+	TcpSockets      int64                `json:"tcpSockets"`
+	UdpSockets      int64                `json:"udpSockets"`
+	UdpRcvbufErrors uint64               `json:"udpRcvbufErrors"` // udp datagrams dropped since boot because a receive buffer was full
+}
+
+// InterfaceMonitorVo the throughput of the interface since the sample before
+type InterfaceMonitorVo struct {
+	Name      string  `json:"name"`
+	RxBytes   float64 `json:"rxBytes"`   // bytes/s
+	TxBytes   float64 `json:"txBytes"`   // bytes/s
+	RxPackets float64 `json:"rxPackets"` // packets/s
+	TxPackets float64 `json:"txPackets"` // packets/s
 }
 
 type Hysteria2MonitorVo struct {
-	UserTotal   int64  `json:"userTotal"`   // 在线用户数
-	DeviceTotal int64  `json:"deviceTotal"` // 在线设备数
+	UserTotal   int64  `json:"userTotal"`   // 在线用户数，所有实例的合计
+	DeviceTotal int64  `json:"deviceTotal"` // 在线设备数，所有实例的合计
 	Version     string `json:"version"`     // 版本
-	Running     bool   `json:"running"`     // 运行状态
+	Hysteria2ProcessMonitorVo
+
+	Inbounds []InboundMonitorVo `json:"inbounds"`
+}
+
+type Hysteria2ProcessMonitorVo struct {
+	Running bool `json:"running"` // 运行状态
+
+	RestartCount  int64 `json:"restartCount"`  // automatic restart attempts after a crash
+	LastExitCode  int   `json:"lastExitCode"`  // exit code of the last crash, -1 when killed by a signal
+	LastCrashTime int64 `json:"lastCrashTime"` // 0 never crashed
+
+	// the latest sample of the process, 0 when not running
+	CpuPercent float64 `json:"cpuPercent"` // of one core
+	Rss        uint64  `json:"rss"`        // bytes
+	Fds        int32   `json:"fds"`
+	Threads    int32   `json:"threads"`
+	Uptime     int64   `json:"uptime"`   // seconds
+	UdpDrops   uint64  `json:"udpDrops"` // datagrams dropped by the udp sockets, e.g. because the receive buffer was full
+}
+
+type InboundMonitorVo struct {
+	Id          int64  `json:"id"`
+	Name        string `json:"name"`
+	UserTotal   int64  `json:"userTotal"`
+	DeviceTotal int64  `json:"deviceTotal"`
+	Hysteria2ProcessMonitorVo
+}
+
+type Hysteria2MetricsVo struct {
+	Time       int64   `json:"time"`
+	CpuPercent float64 `json:"cpuPercent"`
+	Rss        uint64  `json:"rss"`
+	Fds        int32   `json:"fds"`
+	Threads    int32   `json:"threads"`
+	Uptime     int64   `json:"uptime"`
+	UdpDrops   uint64  `json:"udpDrops"`
+}
+
+type SystemMetricsVo struct {
+	Time        int64   `json:"time"` // start of the bucket in milliseconds
+	CpuPercent  float64 `json:"cpuPercent"`
+	MemPercent  float64 `json:"memPercent"`
+	DiskPercent float64 `json:"diskPercent"`
+	Load1       float64 `json:"load1"`
+	Load5       float64 `json:"load5"`
+	Load15      float64 `json:"load15"`
+	NetRecv     float64 `json:"netRecv"` // bytes/s
+	NetSent     float64 `json:"netSent"` // bytes/s
 }
@@ -0,0 +1,17 @@
+package vo
+
+type PlanVo struct {
+	BaseVo
+	Name              string  `json:"name"`
+	Quota             int64   `json:"quota"`
+	Duration          int64   `json:"duration"` // days
+	DeviceNo          int64   `json:"deviceNo"`
+	TrafficMultiplier float64 `json:"trafficMultiplier"`
+	ResetCycle        string  `json:"resetCycle"`
+	ResetInterval     int64   `json:"resetInterval"`
+}
+
+type PlanPageVo struct {
+	PlanVos []PlanVo `json:"records"`
+	Total   int64    `json:"total"`
+}
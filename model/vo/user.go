@@ -0,0 +1,21 @@
+package vo
+
+type UserInfoVo struct {
+	Id           int64  `json:"id"`
+	Username     string `json:"username"`
+	Quota        int64  `json:"quota"`
+	Download     int64  `json:"download"`
+	Upload       int64  `json:"upload"`
+	ExpireTime   int64  `json:"expireTime"`
+	KickUtilTime int64  `json:"kickUtilTime"`
+	DeviceNo     int64  `json:"deviceNo"` // Limit the number of devices
+
+	BillingMode    string `json:"billingMode"`
+	BilledDownload int64  `json:"billedDownload"` // the download counted against the quota by the billing mode
+	BilledUpload   int64  `json:"billedUpload"`   // the upload counted against the quota by the billing mode
+
+	Online bool  `json:"online"` // online status
+	Device int64 `json:"device"` // Number of online devices
+
+	SubscribeUrl string `json:"subscribeUrl"`
+}
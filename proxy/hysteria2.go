@@ -2,11 +2,14 @@ package proxy
 
 import (
 	"errors"
+	"fmt"
 	"github.com/sirupsen/logrus"
+	"h-ui/model/bo"
 	"h-ui/model/constant"
 	"h-ui/util"
 	"os/exec"
 	"sync"
+	"time"
 )
 
 type Hysteria2Process struct {
@@ -23,14 +26,53 @@ func init() {
 	hysteria2Instance = &Hysteria2Process{process{mutex: &mutexHysteria2, cmd: &cmdHysteria2}, util.GetHysteria2BinPath(), constant.Hysteria2ConfigPath}
 }
 
+// hysteria2Inbounds the processes of the inbounds by id, they are created on first use
+var hysteria2Inbounds = map[int64]*Hysteria2Process{}
+var mutexHysteria2Inbounds sync.Mutex
+
 func NewHysteria2Instance() *Hysteria2Process {
 	return hysteria2Instance
 }
 
+// NewHysteria2InboundInstance the process of the inbound, every inbound runs its own hysteria2 with its own config file
+func NewHysteria2InboundInstance(id int64) *Hysteria2Process {
+	mutexHysteria2Inbounds.Lock()
+	defer mutexHysteria2Inbounds.Unlock()
+	instance, exist := hysteria2Inbounds[id]
+	if !exist {
+		instance = &Hysteria2Process{process{mutex: &sync.Mutex{}, cmd: &exec.Cmd{}}, util.GetHysteria2BinPath(), fmt.Sprintf(constant.Hysteria2InboundConfigPath, id)}
+		hysteria2Inbounds[id] = instance
+	}
+	return instance
+}
+
+// RemoveHysteria2InboundInstance forget the process of a deleted inbound, it must be stopped first
+func RemoveHysteria2InboundInstance(id int64) {
+	mutexHysteria2Inbounds.Lock()
+	defer mutexHysteria2Inbounds.Unlock()
+	delete(hysteria2Inbounds, id)
+}
+
+// ListHysteria2InboundInstance the processes of the inbounds that were used since the panel started
+func ListHysteria2InboundInstance() map[int64]*Hysteria2Process {
+	mutexHysteria2Inbounds.Lock()
+	defer mutexHysteria2Inbounds.Unlock()
+	instances := make(map[int64]*Hysteria2Process, len(hysteria2Inbounds))
+	for id, instance := range hysteria2Inbounds {
+		instances[id] = instance
+	}
+	return instances
+}
+
 func (h *Hysteria2Process) IsRunning() bool {
 	return h.isRunning()
 }
 
+// ConfigPath the file the config is written to before starting
+func (h *Hysteria2Process) ConfigPath() string {
+	return h.configPath
+}
+
 func (h *Hysteria2Process) StartHysteria2() error {
 	if err := h.start(h.binPath, "-c", h.configPath, "server"); err != nil {
 		_ = util.RemoveFile(h.configPath)
@@ -40,8 +82,8 @@ func (h *Hysteria2Process) StartHysteria2() error {
 	return nil
 }
 
-func (h *Hysteria2Process) StopHysteria2() error {
-	if err := h.stop(); err != nil {
+func (h *Hysteria2Process) StopHysteria2(grace time.Duration) error {
+	if err := h.stop(grace); err != nil {
 		logrus.Errorf("stop hysteria2 err: %v", err)
 		return errors.New("stop hysteria2 err")
 	}
@@ -56,3 +98,16 @@ func (h *Hysteria2Process) Release() error {
 	}
 	return nil
 }
+
+func (h *Hysteria2Process) Stats() bo.ProcessStats {
+	return h.getStats()
+}
+
+// StartupLogs the first output lines of the running or last exited hysteria2
+func (h *Hysteria2Process) StartupLogs() []string {
+	return h.getLogs()
+}
+
+func (h *Hysteria2Process) Pid() int {
+	return h.pid()
+}
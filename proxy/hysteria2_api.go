@@ -16,19 +16,26 @@ import (
 )
 
 type Hysteria2Api struct {
-	apiPort int64
+	apiPort  int64
+	instance *Hysteria2Process
 }
 
 func NewHysteria2Api(apiPort int64) *Hysteria2Api {
+	return NewHysteria2InstanceApi(NewHysteria2Instance(), apiPort)
+}
+
+// NewHysteria2InstanceApi the traffic stats api of the instance, the calls are skipped while it is not running
+func NewHysteria2InstanceApi(instance *Hysteria2Process, apiPort int64) *Hysteria2Api {
 	return &Hysteria2Api{
-		apiPort: apiPort,
+		apiPort:  apiPort,
+		instance: instance,
 	}
 }
 
 // ListUsers 每个用户的流量信息
 func (h *Hysteria2Api) ListUsers(clear bool, secret string) (map[string]bo.Hysteria2UserTraffic, error) {
 	var users map[string]bo.Hysteria2UserTraffic
-	if !NewHysteria2Instance().IsRunning() {
+	if !h.instance.IsRunning() {
 		return users, nil
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -73,7 +80,7 @@ func (h *Hysteria2Api) ListUsers(clear bool, secret string) (map[string]bo.Hyste
 
 // KickUsers 踢下线
 func (h *Hysteria2Api) KickUsers(keys []string, secret string) error {
-	if !NewHysteria2Instance().IsRunning() {
+	if !h.instance.IsRunning() {
 		return nil
 	}
 	usernamesByte, err := json.Marshal(keys)
@@ -112,7 +119,7 @@ func (h *Hysteria2Api) KickUsers(keys []string, secret string) error {
 // OnlineUsers 在线用户
 func (h *Hysteria2Api) OnlineUsers(secret string) (map[string]int64, error) {
 	var onlineUsers map[string]int64
-	if !NewHysteria2Instance().IsRunning() {
+	if !h.instance.IsRunning() {
 		return onlineUsers, nil
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -5,10 +5,26 @@ import (
 	"errors"
 	"github.com/sirupsen/logrus"
 	"gopkg.in/natefinch/lumberjack.v2"
+	"h-ui/model/bo"
 	"h-ui/model/constant"
 	"io"
+	"os"
 	"os/exec"
 	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+const (
+	restartBackoffMin = time.Second
+	restartBackoffMax = time.Minute
+	// restartMax consecutive crashes before the supervisor gives up
+	restartMax = 5
+	// restartStableAfter a process that ran longer than this is considered healthy again
+	restartStableAfter = 5 * time.Minute
+	// startupLogMax the output lines kept from the last start
+	startupLogMax = 50
 )
 
 var logger logrus.Logger
@@ -33,10 +49,79 @@ func init() {
 type process struct {
 	mutex *sync.Mutex
 	cmd   *exec.Cmd
+	// exited is closed by the supervisor once the current cmd has been reaped
+	exited chan struct{}
+	// stopping marks the next exit as requested so it is not restarted
+	stopping atomic.Bool
+
+	// stateMutex guards cmd and exited against the supervisor, the stats and the logs
+	stateMutex sync.Mutex
+	stats      bo.ProcessStats
+	crashes    int // consecutive crashes
+	logs       []string
 }
 
 func (p *process) isRunning() bool {
-	return p.cmd != nil && p.cmd.Process != nil && p.cmd.ProcessState == nil
+	p.stateMutex.Lock()
+	defer p.stateMutex.Unlock()
+	if p.cmd == nil || p.cmd.Process == nil || p.exited == nil {
+		return false
+	}
+	select {
+	case <-p.exited:
+		return false
+	default:
+		return true
+	}
+}
+
+func (p *process) current() (*exec.Cmd, chan struct{}) {
+	p.stateMutex.Lock()
+	defer p.stateMutex.Unlock()
+	return p.cmd, p.exited
+}
+
+func (p *process) setCurrent(cmd *exec.Cmd, exited chan struct{}) {
+	p.stateMutex.Lock()
+	defer p.stateMutex.Unlock()
+	p.cmd = cmd
+	p.exited = exited
+}
+
+// pid the pid of the running cmd, 0 when it is not running
+func (p *process) pid() int {
+	p.stateMutex.Lock()
+	defer p.stateMutex.Unlock()
+	if p.cmd == nil || p.cmd.Process == nil || p.exited == nil {
+		return 0
+	}
+	select {
+	case <-p.exited:
+		return 0
+	default:
+		return p.cmd.Process.Pid
+	}
+}
+
+func (p *process) getStats() bo.ProcessStats {
+	p.stateMutex.Lock()
+	defer p.stateMutex.Unlock()
+	return p.stats
+}
+
+// getLogs the first output lines of the current cmd
+func (p *process) getLogs() []string {
+	p.stateMutex.Lock()
+	defer p.stateMutex.Unlock()
+	return append([]string(nil), p.logs...)
+}
+
+func (p *process) appendLog(line string) {
+	p.stateMutex.Lock()
+	defer p.stateMutex.Unlock()
+	if len(p.logs) < startupLogMax {
+		p.logs = append(p.logs, line)
+	}
 }
 
 func (p *process) start(name string, arg ...string) error {
@@ -47,6 +132,11 @@ func (p *process) start(name string, arg ...string) error {
 	defer p.mutex.Unlock()
 
 	if p.isRunning() {
+		// the stop is still waiting for it to exit
+		if p.stopping.Load() {
+			logrus.Errorf("start cmd err: the cmd is stopping")
+			return errors.New("start cmd err")
+		}
 		return nil
 	}
 
@@ -73,32 +163,118 @@ func (p *process) start(name string, arg ...string) error {
 		return errors.New("cmd start err")
 	}
 
+	exited := make(chan struct{})
+	p.stateMutex.Lock()
 	p.cmd = cmd
+	p.exited = exited
+	p.logs = nil
+	p.stateMutex.Unlock()
+	p.stopping.Store(false)
 
-	go p.handleLogs(stdout, stderr)
+	go p.supervise(cmd, exited, stdout, stderr, name, arg...)
 
 	return nil
 }
 
-func (p *process) stop() error {
+// supervise reap the cmd and restart it with exponential backoff when it exited without being stopped
+func (p *process) supervise(cmd *exec.Cmd, exited chan struct{}, stdout, stderr io.ReadCloser, name string, arg ...string) {
+	startTime := time.Now()
+	// the pipes must be drained before Wait
+	p.handleLogs(stdout, stderr)
+	err := cmd.Wait()
+	close(exited)
+	if p.stopping.Load() {
+		return
+	}
+
+	p.stateMutex.Lock()
+	p.stats.LastExitCode = cmd.ProcessState.ExitCode()
+	p.stats.LastCrashTime = time.Now().UnixMilli()
+	if time.Since(startTime) > restartStableAfter {
+		p.crashes = 0
+	}
+	p.crashes++
+	crashes := p.crashes
+	p.stateMutex.Unlock()
+	logrus.Errorf("cmd exited unexpectedly: %v exit code: %d", err, cmd.ProcessState.ExitCode())
+
+	for ; crashes <= restartMax; crashes++ {
+		backoff := restartBackoffMin << (crashes - 1)
+		if backoff > restartBackoffMax {
+			backoff = restartBackoffMax
+		}
+		time.Sleep(backoff)
+		// stopped, or started again by someone else in the meantime
+		if current, _ := p.current(); p.stopping.Load() || current != cmd {
+			return
+		}
+		// the stats are settled before the new cmd runs, a crash of it must not race this update
+		p.stateMutex.Lock()
+		p.stats.RestartCount++
+		p.crashes = crashes
+		p.stateMutex.Unlock()
+		if err = p.start(name, arg...); err == nil {
+			logrus.Infof("cmd restarted after %d consecutive crashes", crashes)
+			return
+		}
+	}
+	logrus.Errorf("cmd crashed %d times in a row, giving up", restartMax)
+}
+
+// stop send SIGTERM so the sessions are closed cleanly and SIGKILL when the cmd is still running after the grace timeout,
+// a grace timeout of 0 kills it right away. The lock is only held to signal, the exit is awaited on the channel the
+// supervisor closes so the state and the stats stay readable meanwhile.
+func (p *process) stop(grace time.Duration) error {
 	if !p.mutex.TryLock() {
 		logrus.Errorf("cmd stop err: lock not acquired")
 		return errors.New("cmd stop err")
 	}
-	defer p.mutex.Unlock()
 
+	p.stopping.Store(true)
 	if !p.isRunning() {
+		p.mutex.Unlock()
 		return nil
 	}
 
-	if err := p.cmd.Process.Kill(); err != nil {
+	cmd, exited := p.current()
+	terminated := false
+	if grace > 0 {
+		if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+			logrus.Warnf("cmd terminate err: %v", err)
+		} else {
+			terminated = true
+		}
+	}
+	p.mutex.Unlock()
+
+	if terminated {
+		select {
+		case <-exited:
+			p.clearCurrent(cmd)
+			return nil
+		case <-time.After(grace):
+			logrus.Warnf("cmd did not exit within %s, killing it", grace)
+		}
+	}
+	if err := cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
 		logrus.Errorf("cmd stop err: %v", err)
 		return errors.New("cmd stop err")
 	}
-	p.cmd = nil
+	<-exited
+	p.clearCurrent(cmd)
 	return nil
 }
 
+// clearCurrent forget the cmd unless another one was started since
+func (p *process) clearCurrent(cmd *exec.Cmd) {
+	p.stateMutex.Lock()
+	defer p.stateMutex.Unlock()
+	if p.cmd == cmd {
+		p.cmd = nil
+		p.exited = nil
+	}
+}
+
 func (p *process) release() error {
 	if !p.mutex.TryLock() {
 		logrus.Errorf("cmd release err: lock not acquired")
@@ -106,15 +282,17 @@ func (p *process) release() error {
 	}
 	defer p.mutex.Unlock()
 
+	p.stopping.Store(true)
 	if !p.isRunning() {
 		return nil
 	}
 
-	if err := p.cmd.Process.Release(); err != nil {
+	cmd, _ := p.current()
+	if err := cmd.Process.Release(); err != nil {
 		logrus.Errorf("cmd release err: %v", err)
 		return errors.New("cmd release err")
 	}
-	p.cmd = nil
+	p.setCurrent(nil, nil)
 	return nil
 }
 
@@ -151,12 +329,14 @@ func (p *process) handleLogs(stdout, stderr io.ReadCloser) {
 			if !ok {
 				stdoutChan = nil
 			} else {
+				p.appendLog(line)
 				logger.Infof(line)
 			}
 		case line, ok := <-stderrChan:
 			if !ok {
 				stderrChan = nil
 			} else {
+				p.appendLog(line)
 				logger.Errorf(line)
 			}
 		}
@@ -0,0 +1,106 @@
+package proxy
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// chdirTemp run the test in a temporary directory, the hysteria2 logger writes relative to it
+func chdirTemp(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(dir)
+	})
+}
+
+func TestProcessPidWhileStopping(t *testing.T) {
+	chdirTemp(t)
+	p := &process{mutex: &sync.Mutex{}}
+	if err := p.start("sh", "-c", "while true; do sleep 0.1; done"); err != nil {
+		t.Fatal(err)
+	}
+	if p.pid() == 0 {
+		t.Fatal("pid of the running cmd is 0")
+	}
+
+	// pid races the stop, it must never see the cmd half cleared
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		deadline := time.Now().Add(2 * time.Second)
+		for p.isRunning() && time.Now().Before(deadline) {
+			p.pid()
+		}
+	}()
+	if err := p.stop(0); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	if pid := p.pid(); pid != 0 {
+		t.Errorf("pid of the stopped cmd is %d", pid)
+	}
+}
+
+func TestProcessStopReleasesLock(t *testing.T) {
+	chdirTemp(t)
+	p := &process{mutex: &sync.Mutex{}}
+	// ignores SIGTERM so the stop waits for the whole grace timeout
+	if err := p.start("sh", "-c", "trap '' TERM; while true; do sleep 0.1; done"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(200 * time.Millisecond)
+
+	stopped := make(chan error)
+	go func() {
+		stopped <- p.stop(time.Second)
+	}()
+	time.Sleep(200 * time.Millisecond)
+	if !p.mutex.TryLock() {
+		t.Fatal("the lock is held during the grace timeout")
+	}
+	p.mutex.Unlock()
+	if err := p.start("sh", "-c", "sleep 1"); err == nil {
+		t.Error("started while the previous cmd is stopping")
+	}
+	if err := <-stopped; err != nil {
+		t.Fatal(err)
+	}
+	if p.isRunning() {
+		t.Error("still running after the grace timeout")
+	}
+}
+
+func TestProcessCrashRestartStats(t *testing.T) {
+	chdirTemp(t)
+	p := &process{mutex: &sync.Mutex{}}
+	if err := p.start("sh", "-c", "sleep 0.1; exit 3"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the stats are read while the supervisor records the crash and restarts, the race detector checks the locking
+	deadline := time.Now().Add(5 * time.Second)
+	var stats = p.getStats()
+	for stats.RestartCount == 0 && time.Now().Before(deadline) {
+		p.pid()
+		time.Sleep(10 * time.Millisecond)
+		stats = p.getStats()
+	}
+	if err := p.stop(0); err != nil {
+		t.Fatal(err)
+	}
+	stats = p.getStats()
+	if stats.RestartCount < 1 {
+		t.Fatalf("restart count %d, want at least 1", stats.RestartCount)
+	}
+	if stats.LastExitCode != 3 {
+		t.Fatalf("last exit code %d, want 3", stats.LastExitCode)
+	}
+}
@@ -11,13 +11,27 @@ func initAccountAdminRouter(accountApi *gin.RouterGroup) {
 		account.GET("/pageAccount", controller.PageAccount)
 		account.POST("/saveAccount", controller.SaveAccount)
 		account.POST("/deleteAccount", controller.DeleteAccount)
+		account.GET("/pageTrashAccount", controller.PageTrashAccount)
+		account.POST("/restoreAccount", controller.RestoreAccount)
+		account.POST("/purgeAccount", controller.PurgeAccount)
 		account.POST("/updateAccount", controller.UpdateAccount)
 		account.POST("/resetTraffic", controller.ResetTraffic)
 		account.GET("/getAccountInfo", controller.GetAccountInfo)
 		account.GET("/getAccount", controller.GetAccount)
 		account.POST("/importAccount", controller.ImportAccount)
 		account.POST("/exportAccount", controller.ExportAccount)
+		account.POST("/exportAccountCsv", controller.ExportAccountCsv)
 		account.POST("/releaseKickAccount", controller.ReleaseKickAccount)
 		account.GET("/verifyDefaultPass", controller.VerifyDefaultPass)
+		account.GET("/listAccountTraffic", controller.ListAccountTraffic)
+		account.GET("/rankAccount", controller.RankAccount)
+		account.POST("/exportAccountRankCsv", controller.ExportAccountRankCsv)
+		account.POST("/assignPlan", controller.AssignPlan)
+		account.POST("/renewAccount", controller.RenewAccount)
+		account.POST("/generateAccount", controller.GenerateAccount)
+		account.GET("/listAccountTag", controller.ListAccountTag)
+		account.POST("/saveAccountTag", controller.SaveAccountTag)
+		account.POST("/deleteAccountTag", controller.DeleteAccountTag)
+		account.POST("/accountTagAction", controller.AccountTagAction)
 	}
 }
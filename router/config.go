@@ -13,6 +13,11 @@ func initConfigRouter(configApi *gin.RouterGroup) {
 		config.POST("/listConfig", controller.ListConfig)
 		config.GET("/getHysteria2Config", controller.GetHysteria2Config)
 		config.POST("/updateHysteria2Config", controller.UpdateHysteria2Config)
+		config.POST("/validateHysteria2Config", controller.ValidateHysteria2Config)
+		config.GET("/pageHysteria2ConfigRevision", controller.PageHysteria2ConfigRevision)
+		config.GET("/getHysteria2ConfigRevision", controller.GetHysteria2ConfigRevision)
+		config.GET("/diffHysteria2ConfigRevision", controller.DiffHysteria2ConfigRevision)
+		config.POST("/rollbackHysteria2Config", controller.RollbackHysteria2Config)
 		config.POST("/exportHysteria2Config", controller.ExportHysteria2Config)
 		config.POST("/importHysteria2Config", controller.ImportHysteria2Config)
 		config.POST("/exportConfig", controller.ExportConfig)
@@ -20,6 +20,10 @@ func initHysteria2Router(hysteria2Api *gin.RouterGroup) {
 		hysteria2.POST("/hysteria2Kick", controller.Hysteria2Kick)
 		hysteria2.POST("/hysteria2ChangeVersion", controller.Hysteria2ChangeVersion)
 		hysteria2.GET("/listRelease", controller.ListRelease)
+		hysteria2.POST("/uploadHysteria2", controller.UploadHysteria2)
+		hysteria2.GET("/listHysteria2Version", controller.ListHysteria2Version)
+		hysteria2.POST("/useHysteria2Version", controller.UseHysteria2Version)
+		hysteria2.POST("/rollbackHysteria2Version", controller.RollbackHysteria2Version)
 		hysteria2.GET("/hysteria2SubscribeUrl", controller.Hysteria2SubscribeUrl)
 		hysteria2.GET("/hysteria2Url", controller.Hysteria2Url)
 	}
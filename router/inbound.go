@@ -0,0 +1,18 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"h-ui/controller"
+)
+
+func initInboundRouter(inboundApi *gin.RouterGroup) {
+	inbound := inboundApi.Group("/inbound")
+	{
+		inbound.GET("/pageInbound", controller.PageInbound)
+		inbound.GET("/listInbound", controller.ListInbound)
+		inbound.GET("/getInbound", controller.GetInbound)
+		inbound.POST("/saveInbound", controller.SaveInbound)
+		inbound.POST("/updateInbound", controller.UpdateInbound)
+		inbound.POST("/deleteInbound", controller.DeleteInbound)
+	}
+}
@@ -10,5 +10,7 @@ func initMonitorRouter(accountApi *gin.RouterGroup) {
 	{
 		account.GET("/monitorSystem", controller.MonitorSystem)
 		account.GET("/monitorHysteria2", controller.MonitorHysteria2)
+		account.GET("/listHysteria2Metrics", controller.ListHysteria2Metrics)
+		account.GET("/listSystemMetrics", controller.ListSystemMetrics)
 	}
 }
@@ -0,0 +1,18 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"h-ui/controller"
+)
+
+func initPlanRouter(planApi *gin.RouterGroup) {
+	plan := planApi.Group("/plan")
+	{
+		plan.GET("/pagePlan", controller.PagePlan)
+		plan.GET("/listPlan", controller.ListPlan)
+		plan.GET("/getPlan", controller.GetPlan)
+		plan.POST("/savePlan", controller.SavePlan)
+		plan.POST("/updatePlan", controller.UpdatePlan)
+		plan.POST("/deletePlan", controller.DeletePlan)
+	}
+}
@@ -2,6 +2,7 @@ package router
 
 import (
 	"github.com/gin-gonic/gin"
+	"h-ui/controller"
 	"h-ui/frontend"
 	"h-ui/middleware"
 )
@@ -12,14 +13,22 @@ func Router(router *gin.Engine, huiWebContext *string) {
 
 	frontend.InitFrontend(router, huiWebContext)
 
+	router.GET("/metrics", middleware.MetricsHandler(), controller.Metrics)
+
 	authApi := router.Group("/hui")
 	{
 		initAuthRouter(authApi)
 		initHysteria2AuthRouter(authApi)
+		initUserAuthRouter(authApi)
 	}
 
 	router.Use(middleware.JWTHandler())
 
+	huiUserApi := router.Group("/hui", middleware.UserHandler())
+	{
+		initUserRouter(huiUserApi)
+	}
+
 	router.Use(middleware.AdminHandler())
 
 	huiAdminApi := router.Group("/hui")
@@ -29,5 +38,7 @@ func Router(router *gin.Engine, huiWebContext *string) {
 		initHysteria2Router(huiAdminApi)
 		initLogRouter(huiAdminApi)
 		initMonitorRouter(huiAdminApi)
+		initPlanRouter(huiAdminApi)
+		initInboundRouter(huiAdminApi)
 	}
 }
@@ -0,0 +1,21 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"h-ui/controller"
+)
+
+func initUserAuthRouter(userApi *gin.RouterGroup) {
+	user := userApi.Group("/user")
+	{
+		user.POST("/login", controller.UserLogin)
+	}
+}
+
+func initUserRouter(userApi *gin.RouterGroup) {
+	user := userApi.Group("/user")
+	{
+		user.GET("/getUserInfo", controller.GetUserInfo)
+		user.POST("/updateConPass", controller.UpdateUserConPass)
+	}
+}
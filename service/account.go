@@ -4,30 +4,68 @@ import (
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 	"h-ui/dao"
 	"h-ui/model/bo"
 	"h-ui/model/constant"
 	"h-ui/model/dto"
 	"h-ui/model/entity"
 	"h-ui/model/vo"
+	"h-ui/util"
+	"strconv"
+	"time"
 )
 
 func Login(username string, pass string) (string, error) {
-	account, err := dao.GetAccount("username = ? and pass = ? and role = 'admin' and deleted = 0", username, pass)
+	_, token, err := login(username, pass, "admin")
+	return token, err
+}
+
+func UserLogin(username string, pass string) (string, error) {
+	account, token, err := login(username, pass, "user")
 	if err != nil {
 		return "", err
 	}
+	// 更新最近登录时间
+	if err = dao.UpdateAccount([]int64{*account.Id}, map[string]interface{}{"login_at": time.Now().UnixMilli()}); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func login(username string, pass string, role string) (entity.Account, string, error) {
+	account, err := dao.GetAccount("username = ? and role = ? and deleted = 0 and trashed_at = 0", username, role)
+	if err != nil {
+		return account, "", err
+	}
+	ok, upgrade := util.VerifyPassword(*account.Pass, pass)
+	if !ok {
+		return account, "", errors.New(constant.WrongPassword)
+	}
+	// 旧的 SHA-224 密码登录成功后升级
+	if upgrade {
+		if passEncrypt, err := util.HashPassword(pass); err != nil {
+			logrus.Errorf("upgrade password hash err: %v", err)
+		} else {
+			_ = dao.UpdateAccount([]int64{*account.Id}, map[string]interface{}{"pass": passEncrypt})
+		}
+	}
 	accountBo := bo.AccountBo{
 		Id:       *account.Id,
 		Username: *account.Username,
 		Roles:    []string{*account.Role},
 		Deleted:  *account.Deleted,
 	}
-	return GenToken(accountBo)
+	token, err := GenToken(accountBo)
+	return account, token, err
 }
 
 func PageAccount(accountPageDto dto.AccountPageDto) ([]entity.Account, int64, error) {
-	return dao.PageAccount(accountPageDto)
+	return dao.PageAccount(accountPageDto, false)
+}
+
+func PageTrashAccount(accountPageDto dto.AccountPageDto) ([]entity.Account, int64, error) {
+	return dao.PageAccount(accountPageDto, true)
 }
 
 func SaveAccount(account entity.Account) error {
@@ -35,8 +73,48 @@ func SaveAccount(account entity.Account) error {
 	return err
 }
 
+// DeleteAccount move the accounts to the trash, they can no longer connect but keep their history
 func DeleteAccount(ids []int64) error {
-	return dao.DeleteAccount(ids)
+	accounts, err := dao.ListAccount("id in ? and trashed_at = 0", ids)
+	if err != nil {
+		return err
+	}
+	if len(accounts) == 0 {
+		return nil
+	}
+	var trashIds []int64
+	var usernames []string
+	for _, item := range accounts {
+		trashIds = append(trashIds, *item.Id)
+		usernames = append(usernames, *item.Username)
+	}
+	if err = dao.UpdateAccount(trashIds, map[string]interface{}{"trashed_at": time.Now().UnixMilli()}); err != nil {
+		return err
+	}
+	if Hysteria2AnyRunning() {
+		return kickUsers(usernames)
+	}
+	return nil
+}
+
+func RestoreAccount(ids []int64) error {
+	return dao.UpdateAccount(ids, map[string]interface{}{"trashed_at": 0})
+}
+
+// PurgeAccount permanently delete the accounts in the trash with their traffic history
+func PurgeAccount(ids []int64) error {
+	accounts, err := dao.ListAccount("id in ? and trashed_at > 0", ids)
+	if err != nil {
+		return err
+	}
+	var purgeIds []int64
+	for _, item := range accounts {
+		purgeIds = append(purgeIds, *item.Id)
+	}
+	if len(purgeIds) == 0 {
+		return nil
+	}
+	return dao.PurgeAccount(purgeIds)
 }
 
 func UpdateAccount(account entity.Account) error {
@@ -74,11 +152,23 @@ func UpdateAccount(account entity.Account) error {
 	if account.ConAt != nil && *account.ConAt > 0 {
 		updates["con_at"] = *account.ConAt
 	}
+	if account.ResetCycle != nil {
+		updates["reset_cycle"] = *account.ResetCycle
+	}
+	if account.ResetInterval != nil {
+		updates["reset_interval"] = *account.ResetInterval
+	}
+	if account.TrafficMultiplier != nil {
+		updates["traffic_multiplier"] = *account.TrafficMultiplier
+	}
+	if account.BillingMode != nil {
+		updates["billing_mode"] = *account.BillingMode
+	}
 	return dao.UpdateAccount([]int64{*account.Id}, updates)
 }
 
 func ResetTraffic(id int64) error {
-	return dao.UpdateAccount([]int64{id}, map[string]interface{}{"download": 0, "upload": 0})
+	return dao.UpdateAccount([]int64{id}, map[string]interface{}{"download": 0, "upload": 0, "reset_at": time.Now().UnixMilli()})
 }
 
 func ExistAccountUsername(username string, id int64) bool {
@@ -124,6 +214,15 @@ func ListExportAccount() ([]bo.AccountExport, error) {
 			UpdateTime:   *item.UpdateTime,
 			LoginAt:      *item.LoginAt,
 			ConAt:        *item.ConAt,
+			PlanId:       *item.PlanId,
+
+			ResetCycle:    *item.ResetCycle,
+			ResetInterval: *item.ResetInterval,
+			ResetAnchor:   *item.ResetAnchor,
+			ResetAt:       *item.ResetAt,
+
+			TrafficMultiplier: *item.TrafficMultiplier,
+			BillingMode:       *item.BillingMode,
 		}
 		accountExports = append(accountExports, accountExport)
 	}
@@ -134,10 +233,6 @@ func ReleaseKickAccount(id int64) error {
 	return dao.UpdateAccount([]int64{id}, map[string]interface{}{"kick_util_time": 0})
 }
 
-func UpsertAccount(accounts []entity.Account) error {
-	return dao.UpsertAccount(accounts)
-}
-
 func GetAccountInfo(c *gin.Context) (vo.AccountInfoVo, error) {
 	myClaims, err := ParseToken(GetToken(c))
 	if err != nil {
@@ -152,3 +247,69 @@ func GetAccountInfo(c *gin.Context) (vo.AccountInfoVo, error) {
 		Roles:    myClaims.AccountBo.Roles,
 	}, nil
 }
+
+// GetUserAccount the account of the user who owns the current token
+func GetUserAccount(c *gin.Context) (entity.Account, error) {
+	myClaims, err := ParseToken(GetToken(c))
+	if err != nil {
+		return entity.Account{}, err
+	}
+	account, err := dao.GetAccount("id = ? and role = 'user' and trashed_at = 0", myClaims.AccountBo.Id)
+	if err != nil {
+		return entity.Account{}, err
+	}
+	if *account.Deleted != 0 {
+		return entity.Account{}, errors.New("this account has been disabled")
+	}
+	return account, nil
+}
+
+// UpdateUserConPass rotate the connection password and drop the sessions using the old one
+func UpdateUserConPass(account entity.Account, conPass string) error {
+	if err := dao.UpdateAccount([]int64{*account.Id}, map[string]interface{}{
+		"con_pass": fmt.Sprintf("%s.%s", *account.Username, conPass),
+	}); err != nil {
+		return err
+	}
+	if Hysteria2AnyRunning() {
+		return kickUsers([]string{*account.Username})
+	}
+	return nil
+}
+
+// IsDefaultPass whether the account still uses the password it was seeded with
+func IsDefaultPass(account entity.Account) bool {
+	if account.Pass == nil || *account.Pass == "" {
+		return false
+	}
+	ok, _ := util.VerifyPassword(*account.Pass, constant.DefaultPass)
+	return ok
+}
+
+// CronPurgeAccountTrash purge the accounts that stayed in the trash longer than the retention days, 0 keeps them forever
+func CronPurgeAccountTrash() {
+	retention, err := dao.GetConfig("key = ?", constant.AccountTrashRetention)
+	if err != nil {
+		return
+	}
+	days, err := strconv.ParseInt(*retention.Value, 10, 64)
+	if err != nil {
+		logrus.Errorf("account trash retention string conv int64 err: %v", err)
+		return
+	}
+	if days <= 0 {
+		return
+	}
+	before := time.Now().AddDate(0, 0, -int(days)).UnixMilli()
+	accounts, err := dao.ListAccount("trashed_at > 0 and trashed_at < ?", before)
+	if err != nil {
+		return
+	}
+	var ids []int64
+	for _, item := range accounts {
+		ids = append(ids, *item.Id)
+	}
+	if len(ids) > 0 {
+		_ = PurgeAccount(ids)
+	}
+}
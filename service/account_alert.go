@@ -0,0 +1,174 @@
+package service
+
+import (
+	"fmt"
+	"h-ui/dao"
+	"h-ui/model/constant"
+	"h-ui/model/entity"
+	"h-ui/util"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// alertAccountThreshold send a telegram alert for every quota or expiry threshold the accounts crossed since the last sync,
+// a sent alert is kept until the account falls below the threshold again so it fires once per cycle
+func alertAccountThreshold() {
+	configs, err := dao.ListConfig("key in ?", []string{
+		constant.TelegramEnable,
+		constant.TelegramChatId,
+		constant.TelegramAlertJobEnable,
+		constant.TelegramAlertQuotaThreshold,
+		constant.TelegramAlertExpireThreshold})
+	if err != nil {
+		return
+	}
+	var telegramEnable, telegramChatId, telegramAlertJobEnable, quotaThreshold, expireThreshold = "0", "", "0", "", ""
+	for _, item := range configs {
+		if item.Value != nil {
+			key := *item.Key
+			value := *item.Value
+			if key == constant.TelegramEnable {
+				telegramEnable = value
+			} else if key == constant.TelegramChatId {
+				telegramChatId = value
+			} else if key == constant.TelegramAlertJobEnable {
+				telegramAlertJobEnable = value
+			} else if key == constant.TelegramAlertQuotaThreshold {
+				quotaThreshold = value
+			} else if key == constant.TelegramAlertExpireThreshold {
+				expireThreshold = value
+			}
+		}
+	}
+	if telegramEnable != "1" || telegramChatId == "" || telegramAlertJobEnable != "1" {
+		return
+	}
+	chatId, err := strconv.ParseInt(telegramChatId, 10, 64)
+	if err != nil {
+		logrus.Errorf("parse chatId err: %v", err)
+		return
+	}
+	quotaThresholds, err := util.ParseThresholds(quotaThreshold)
+	if err != nil {
+		logrus.Errorf("telegram alert quota threshold err: %v", err)
+		return
+	}
+	expireThresholds, err := util.ParseThresholds(expireThreshold)
+	if err != nil {
+		logrus.Errorf("telegram alert expire threshold err: %v", err)
+		return
+	}
+
+	now := time.Now().UnixMilli()
+	accounts, err := listAlertAccount(quotaThresholds, expireThresholds, now)
+	if err != nil {
+		return
+	}
+	accountAlerts, err := dao.ListAccountAlert(nil, nil)
+	if err != nil {
+		return
+	}
+	sent := map[string]int64{}
+	for _, item := range accountAlerts {
+		sent[fmt.Sprintf("%d-%s-%d", *item.AccountId, *item.Kind, *item.Threshold)] = *item.Id
+	}
+
+	var rearmIds []int64
+	for _, account := range accounts {
+		var usedPercent int64 = -1
+		if *account.Quota > 0 {
+			usedPercent = util.BilledTraffic(*account.BillingMode, *account.Download, *account.Upload) * 100 / *account.Quota
+		}
+
+		var crossed []entity.AccountAlert
+		var messages []string
+		for _, kind := range []string{constant.AlertKindQuota, constant.AlertKindExpire} {
+			thresholds := quotaThresholds
+			if kind == constant.AlertKindExpire {
+				thresholds = expireThresholds
+			}
+			// the thresholds are in descending order, only the most severe new one is reported
+			reported := false
+			for _, threshold := range thresholds {
+				var reached bool
+				if kind == constant.AlertKindQuota {
+					reached = usedPercent >= threshold
+				} else {
+					reached = *account.ExpireTime-now <= threshold*int64(24*time.Hour/time.Millisecond)
+				}
+				id, exist := sent[fmt.Sprintf("%d-%s-%d", *account.Id, kind, threshold)]
+				if !reached {
+					if exist {
+						rearmIds = append(rearmIds, id)
+					}
+					continue
+				}
+				if exist {
+					continue
+				}
+				alertKind, alertThreshold := kind, threshold
+				crossed = append(crossed, entity.AccountAlert{AccountId: account.Id, Kind: &alertKind, Threshold: &alertThreshold})
+				if !reported {
+					reported = true
+					messages = append(messages, accountAlertText(account, kind, usedPercent, now))
+				}
+			}
+		}
+
+		if len(crossed) == 0 {
+			continue
+		}
+		sendErr := false
+		for _, message := range messages {
+			if err = SendWithMessage(chatId, message); err != nil {
+				sendErr = true
+				break
+			}
+		}
+		// retried on the next sync when telegram could not be reached
+		if sendErr {
+			continue
+		}
+		_ = dao.SaveAccountAlert(crossed)
+	}
+	if len(rearmIds) > 0 {
+		_ = dao.DeleteAccountAlert("id in ?", rearmIds)
+	}
+}
+
+// listAlertAccount the accounts that are not expired and reach the lowest threshold, or have an alert that may be
+// rearmed, the thresholds are in descending order
+func listAlertAccount(quotaThresholds []int64, expireThresholds []int64, now int64) ([]entity.Account, error) {
+	conditions := []string{"id in (select account_id from account_alert)"}
+	args := []interface{}{now}
+	if len(quotaThresholds) > 0 {
+		conditions = append(conditions, "(quota > 0 and "+dao.AccountBilledTraffic+" * 100 >= quota * ?)")
+		args = append(args, quotaThresholds[len(quotaThresholds)-1])
+	}
+	if len(expireThresholds) > 0 {
+		conditions = append(conditions, "expire_time <= ?")
+		args = append(args, now+expireThresholds[0]*int64(24*time.Hour/time.Millisecond))
+	}
+	return dao.ListAccount("role = 'user' and deleted = 0 and trashed_at = 0 and expire_time > ? and ("+
+		strings.Join(conditions, " or ")+")", args...)
+}
+
+func accountAlertText(account entity.Account, kind string, usedPercent int64, now int64) string {
+	if kind == constant.AlertKindQuota {
+		used := float64(util.BilledTraffic(*account.BillingMode, *account.Download, *account.Upload)) / (1 << 30)
+		quota := float64(*account.Quota) / (1 << 30)
+		if usedPercent >= 100 {
+			return fmt.Sprintf("%s has used up its quota, %.2f GiB of %.2f GiB", *account.Username, used, quota)
+		}
+		return fmt.Sprintf("%s has used %d%% of its quota, %.2f GiB of %.2f GiB", *account.Username, usedPercent, used, quota)
+	}
+	expireTime := time.UnixMilli(*account.ExpireTime).Format("2006-01-02 15:04:05")
+	left := time.Duration(*account.ExpireTime-now) * time.Millisecond
+	if left < 24*time.Hour {
+		return fmt.Sprintf("%s expires within a day at %s", *account.Username, expireTime)
+	}
+	return fmt.Sprintf("%s expires in %d days at %s", *account.Username, int64(left/(24*time.Hour)), expireTime)
+}
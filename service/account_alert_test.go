@@ -0,0 +1,59 @@
+package service
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"h-ui/dao"
+	"h-ui/model/entity"
+)
+
+func TestListAlertAccount(t *testing.T) {
+	initTestDb(t)
+	now := time.Now().UnixMilli()
+	day := int64(24 * time.Hour / time.Millisecond)
+	saveTestAccount(t, "quiet", map[string]interface{}{"quota": 100, "download": 10})
+	saveTestAccount(t, "unlimited", map[string]interface{}{"download": 1000})
+	saveTestAccount(t, "quota", map[string]interface{}{"quota": 100, "download": 50, "upload": 30})
+	saveTestAccount(t, "billed", map[string]interface{}{"quota": 100, "download": 50, "upload": 30, "billing_mode": "upload"})
+	saveTestAccount(t, "expiring", map[string]interface{}{"expire_time": now + 2*day})
+	saveTestAccount(t, "expired", map[string]interface{}{"quota": 100, "download": 100, "expire_time": now - day})
+	saveTestAccount(t, "trashed", map[string]interface{}{"quota": 100, "download": 100, "trashed_at": now})
+	alerted := saveTestAccount(t, "alerted", nil)
+	kind := "quota"
+	var threshold int64 = 80
+	if err := dao.SaveAccountAlert([]entity.AccountAlert{{AccountId: &alerted, Kind: &kind, Threshold: &threshold}}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		quotaThresholds  []int64
+		expireThresholds []int64
+		want             []string
+	}{
+		{[]int64{100, 80}, []int64{7, 3}, []string{"alerted", "expiring", "quota"}},
+		{[]int64{100}, []int64{1}, []string{"alerted"}},
+		{nil, nil, []string{"alerted"}},
+	} {
+		accounts, err := listAlertAccount(tt.quotaThresholds, tt.expireThresholds, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, account := range accounts {
+			got = append(got, *account.Username)
+		}
+		sort.Strings(got)
+		if len(got) != len(tt.want) {
+			t.Errorf("thresholds %v %v: got %v, want %v", tt.quotaThresholds, tt.expireThresholds, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("thresholds %v %v: got %v, want %v", tt.quotaThresholds, tt.expireThresholds, got, tt.want)
+				break
+			}
+		}
+	}
+}
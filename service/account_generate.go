@@ -0,0 +1,163 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"h-ui/dao"
+	"h-ui/model/bo"
+	"h-ui/model/dto"
+	"h-ui/model/entity"
+	"h-ui/util"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GenerateAccount create the accounts of the pattern in one transaction with random passwords
+func GenerateAccount(accountGenerateDto dto.AccountGenerateDto) ([]bo.AccountGenerate, error) {
+	var plan entity.Plan
+	var err error
+	if accountGenerateDto.PlanId != nil {
+		if plan, err = dao.GetPlan("id = ?", *accountGenerateDto.PlanId); err != nil {
+			return nil, err
+		}
+		if accountGenerateDto.Quota == nil {
+			accountGenerateDto.Quota = plan.Quota
+		}
+		if accountGenerateDto.ExpireTime == nil {
+			expireTime := time.Now().AddDate(0, 0, int(*plan.Duration)).UnixMilli()
+			accountGenerateDto.ExpireTime = &expireTime
+		}
+		if accountGenerateDto.DeviceNo == nil {
+			accountGenerateDto.DeviceNo = plan.DeviceNo
+		}
+	}
+
+	usernames, err := generateUsernames(*accountGenerateDto.Pattern, *accountGenerateDto.Count, accountGenerateDto.Start)
+	if err != nil {
+		return nil, err
+	}
+	for _, usernameList := range util.SplitArr(usernames, 100) {
+		exists, err := dao.ListAccount("username in ?", usernameList)
+		if err != nil {
+			return nil, err
+		}
+		if len(exists) > 0 {
+			return nil, fmt.Errorf("username %s already exists", *exists[0].Username)
+		}
+	}
+
+	// bcrypt is slow on purpose, hash the passwords concurrently
+	accountGenerates := make([]bo.AccountGenerate, len(usernames))
+	passEncrypts := make([]string, len(usernames))
+	indexes := make([]int, len(usernames))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	var wg sync.WaitGroup
+	var genErr error
+	var genErrOnce sync.Once
+	for _, indexList := range util.SplitArr(indexes, 20) {
+		wg.Add(1)
+		go func(indexList []int) {
+			defer wg.Done()
+			for _, i := range indexList {
+				accountGenerate, passEncrypt, err := generateCredential(usernames[i])
+				if err != nil {
+					genErrOnce.Do(func() { genErr = err })
+					return
+				}
+				accountGenerates[i] = accountGenerate
+				passEncrypts[i] = passEncrypt
+			}
+		}(indexList)
+	}
+	wg.Wait()
+	if genErr != nil {
+		logrus.Errorf("generate account credential err: %v", genErr)
+		return nil, errors.New("generate password err")
+	}
+
+	accounts := make([]entity.Account, len(usernames))
+	for i := range accountGenerates {
+		accounts[i] = entity.Account{
+			Username:      &accountGenerates[i].Username,
+			Pass:          &passEncrypts[i],
+			ConPass:       &accountGenerates[i].ConPass,
+			Quota:         accountGenerateDto.Quota,
+			ExpireTime:    accountGenerateDto.ExpireTime,
+			DeviceNo:      accountGenerateDto.DeviceNo,
+			PlanId:        accountGenerateDto.PlanId,
+			ResetCycle:    plan.ResetCycle,
+			ResetInterval: plan.ResetInterval,
+		}
+	}
+	if err = dao.CreateAccount(accounts); err != nil {
+		return nil, err
+	}
+
+	hostname := strings.Split(*accountGenerateDto.Host, ":")[0]
+	// the link to the first instance, hysteria2 not configured yet leaves it empty
+	instances, err := listHysteria2Instance()
+	if err != nil {
+		return nil, err
+	}
+	for i := range accountGenerates {
+		accountGenerates[i].SubscribeUrl = hysteria2SubscribeUrl(accountGenerates[i].ConPass, *accountGenerateDto.Protocol, *accountGenerateDto.Host)
+		if len(instances) > 0 {
+			accountGenerates[i].Hysteria2Url = hysteria2Url(instances[0], accountGenerates[i].ConPass, hostname)
+		}
+	}
+	return accountGenerates, nil
+}
+
+func generateCredential(username string) (bo.AccountGenerate, string, error) {
+	pass, err := util.RandomString(12)
+	if err != nil {
+		return bo.AccountGenerate{}, "", err
+	}
+	conPass, err := util.RandomString(12)
+	if err != nil {
+		return bo.AccountGenerate{}, "", err
+	}
+	passEncrypt, err := util.HashPassword(pass)
+	if err != nil {
+		return bo.AccountGenerate{}, "", err
+	}
+	return bo.AccountGenerate{
+		Username: username,
+		Pass:     pass,
+		ConPass:  fmt.Sprintf("%s.%s", username, conPass),
+	}, passEncrypt, nil
+}
+
+// AccountGenerateCsv the records of the generated accounts, the first one is the header
+func AccountGenerateCsv(accountGenerates []bo.AccountGenerate) [][]string {
+	records := [][]string{{"username", "pass", "conPass", "subscribeUrl", "hysteria2Url"}}
+	for _, item := range accountGenerates {
+		records = append(records, []string{item.Username, item.Pass, item.ConPass, item.SubscribeUrl, item.Hysteria2Url})
+	}
+	return records
+}
+
+func generateUsernames(pattern string, count int64, start *int64) ([]string, error) {
+	if !strings.Contains(pattern, "{n}") {
+		pattern += "{n}"
+	}
+	var first int64 = 1
+	if start != nil {
+		first = *start
+	}
+	width := len(strconv.FormatInt(first+count-1, 10))
+	usernames := make([]string, 0, count)
+	for i := first; i < first+count; i++ {
+		username := strings.ReplaceAll(pattern, "{n}", fmt.Sprintf("%0*d", width, i))
+		if !util.IsValidStr(username) {
+			return nil, fmt.Errorf("username %s is invalid", username)
+		}
+		usernames = append(usernames, username)
+	}
+	return usernames, nil
+}
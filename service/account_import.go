@@ -0,0 +1,405 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"h-ui/dao"
+	"h-ui/model/constant"
+	"h-ui/model/dto"
+	"h-ui/model/entity"
+	"h-ui/model/vo"
+	"h-ui/util"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// accountImportColumns the csv header of the export, an import may leave out any column except username
+var accountImportColumns = []string{"username", "pass", "conPass", "quota", "download", "upload", "expireTime", "deviceNo", "kickUtilTime", "role", "deleted", "planId", "resetCycle", "resetInterval", "trafficMultiplier", "billingMode"}
+
+type accountImportRow struct {
+	vo.AccountImportRowVo
+	row     dto.AccountImportDto
+	updates map[string]interface{}
+	id      int64
+}
+
+// ImportAccount validate every row of the json or csv file and commit only when all rows are valid,
+// validateRow returns the validation errors of the row, create is true when the username does not exist yet
+func ImportAccount(fileName string, content []byte, dryRun bool, validateRow func(row dto.AccountImportDto, create bool) []string) (vo.AccountImportVo, error) {
+	var rows []accountImportRow
+	var err error
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".json":
+		rows, err = parseAccountImportJson(content)
+	case ".csv":
+		rows, err = parseAccountImportCsv(content)
+	default:
+		return vo.AccountImportVo{}, errors.New("file format not supported")
+	}
+	if err != nil {
+		return vo.AccountImportVo{}, err
+	}
+
+	if err = previewAccountImport(rows, validateRow); err != nil {
+		return vo.AccountImportVo{}, err
+	}
+
+	accountImportVo := vo.AccountImportVo{Rows: []vo.AccountImportRowVo{}}
+	for _, item := range rows {
+		switch item.Action {
+		case constant.ImportActionCreate:
+			accountImportVo.Create++
+		case constant.ImportActionUpdate:
+			accountImportVo.Update++
+		case constant.ImportActionSkip:
+			accountImportVo.Skip++
+		default:
+			accountImportVo.Invalid++
+		}
+		if item.Errors == nil {
+			item.Errors = []string{}
+		}
+		accountImportVo.Rows = append(accountImportVo.Rows, item.AccountImportRowVo)
+	}
+	if dryRun {
+		return accountImportVo, nil
+	}
+	if accountImportVo.Invalid > 0 {
+		return accountImportVo, fmt.Errorf("%d rows are invalid, nothing was imported", accountImportVo.Invalid)
+	}
+	return accountImportVo, commitAccountImport(rows)
+}
+
+// AccountExportCsv the csv records of all accounts, they can be imported again
+func AccountExportCsv() ([][]string, error) {
+	accountExports, err := ListExportAccount()
+	if err != nil {
+		return nil, err
+	}
+	records := [][]string{accountImportColumns}
+	for _, item := range accountExports {
+		records = append(records, []string{
+			item.Username,
+			item.Pass,
+			item.ConPass,
+			strconv.FormatInt(item.Quota, 10),
+			strconv.FormatInt(item.Download, 10),
+			strconv.FormatInt(item.Upload, 10),
+			strconv.FormatInt(item.ExpireTime, 10),
+			strconv.FormatInt(item.DeviceNo, 10),
+			strconv.FormatInt(item.KickUtilTime, 10),
+			item.Role,
+			strconv.FormatInt(item.Deleted, 10),
+			strconv.FormatInt(item.PlanId, 10),
+			item.ResetCycle,
+			strconv.FormatInt(item.ResetInterval, 10),
+			strconv.FormatFloat(item.TrafficMultiplier, 'f', -1, 64),
+			item.BillingMode,
+		})
+	}
+	return records, nil
+}
+
+func parseAccountImportJson(content []byte) ([]accountImportRow, error) {
+	var accounts []dto.AccountImportDto
+	if err := json.Unmarshal(content, &accounts); err != nil {
+		return nil, errors.New("content Unmarshal err")
+	}
+	var rows []accountImportRow
+	for i, item := range accounts {
+		rows = append(rows, accountImportRow{
+			AccountImportRowVo: vo.AccountImportRowVo{Line: i + 1},
+			row:                item,
+		})
+	}
+	return rows, nil
+}
+
+// parseAccountImportCsv an empty cell leaves the field unchanged, so a truncated row never resets the counters
+func parseAccountImportCsv(content []byte) ([]accountImportRow, error) {
+	reader := csv.NewReader(strings.NewReader(strings.TrimPrefix(string(content), "\ufeff")))
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("csv header read err")
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	if _, ok := columns["username"]; !ok {
+		return nil, errors.New("csv column username not exist")
+	}
+
+	var rows []accountImportRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("csv line %d read err", line)
+		}
+		item := accountImportRow{AccountImportRowVo: vo.AccountImportRowVo{Line: line}}
+		cell := func(name string) *string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return nil
+			}
+			value := strings.TrimSpace(record[i])
+			if value == "" {
+				return nil
+			}
+			return &value
+		}
+		number := func(name string) *int64 {
+			value := cell(name)
+			if value == nil {
+				return nil
+			}
+			n, err := strconv.ParseInt(*value, 10, 64)
+			if err != nil {
+				item.Errors = append(item.Errors, fmt.Sprintf("%s is not a number", name))
+				return nil
+			}
+			return &n
+		}
+		decimal := func(name string) *float64 {
+			value := cell(name)
+			if value == nil {
+				return nil
+			}
+			f, err := strconv.ParseFloat(*value, 64)
+			if err != nil {
+				item.Errors = append(item.Errors, fmt.Sprintf("%s is not a number", name))
+				return nil
+			}
+			return &f
+		}
+		item.row = dto.AccountImportDto{
+			Username:      cell("username"),
+			Pass:          cell("pass"),
+			ConPass:       cell("conPass"),
+			Quota:         number("quota"),
+			Download:      number("download"),
+			Upload:        number("upload"),
+			ExpireTime:    number("expireTime"),
+			DeviceNo:      number("deviceNo"),
+			KickUtilTime:  number("kickUtilTime"),
+			Role:          cell("role"),
+			Deleted:       number("deleted"),
+			PlanId:        number("planId"),
+			ResetCycle:    cell("resetCycle"),
+			ResetInterval: number("resetInterval"),
+
+			TrafficMultiplier: decimal("trafficMultiplier"),
+			BillingMode:       cell("billingMode"),
+		}
+		rows = append(rows, item)
+	}
+	return rows, nil
+}
+
+func previewAccountImport(rows []accountImportRow, validateRow func(row dto.AccountImportDto, create bool) []string) error {
+	var usernames []string
+	planRows := false
+	for _, item := range rows {
+		if item.row.Username != nil {
+			usernames = append(usernames, *item.row.Username)
+		}
+		if item.row.PlanId != nil && *item.row.PlanId > 0 {
+			planRows = true
+		}
+	}
+	existing := map[string]entity.Account{}
+	for _, batch := range util.SplitArr(usernames, 500) {
+		accounts, err := dao.ListAccount("username in ?", batch)
+		if err != nil {
+			return err
+		}
+		for _, account := range accounts {
+			existing[*account.Username] = account
+		}
+	}
+	plans := map[int64]bool{}
+	if planRows {
+		planList, err := dao.ListPlan(nil, nil)
+		if err != nil {
+			return err
+		}
+		for _, plan := range planList {
+			plans[*plan.Id] = true
+		}
+	}
+
+	seen := map[string]int{}
+	for i := range rows {
+		item := &rows[i]
+		row := &item.row
+		if row.Username != nil {
+			item.Username = *row.Username
+			// the export holds the full connection password, the rules apply to the part after the username
+			if row.ConPass != nil {
+				conPass := strings.TrimPrefix(*row.ConPass, *row.Username+".")
+				row.ConPass = &conPass
+			}
+			if line, ok := seen[*row.Username]; ok {
+				item.Errors = append(item.Errors, fmt.Sprintf("username is duplicated on line %d", line))
+			} else {
+				seen[*row.Username] = item.Line
+			}
+		}
+
+		if row.PlanId != nil && *row.PlanId > 0 && !plans[*row.PlanId] {
+			item.Errors = append(item.Errors, fmt.Sprintf("plan %d not exist", *row.PlanId))
+		}
+
+		// an export writes 0 when the account has no day cycle
+		if row.ResetInterval != nil && *row.ResetInterval == 0 {
+			row.ResetInterval = nil
+		}
+
+		account, ok := existing[item.Username]
+		if !ok && row.Deleted == nil {
+			var deleted int64 = 0
+			row.Deleted = &deleted
+		}
+		// like the manual edit an import never makes or changes an admin, an export holds the admin unchanged so it is skipped
+		admin := ok && *account.Role == "admin"
+		if row.Role != nil && *row.Role == "admin" && !admin {
+			item.Errors = append(item.Errors, "role admin cannot be imported")
+		}
+		item.Errors = append(item.Errors, validateRow(*row, !ok)...)
+		if len(item.Errors) > 0 {
+			item.Action = constant.ImportActionInvalid
+			continue
+		}
+		if !ok {
+			item.Action = constant.ImportActionCreate
+			continue
+		}
+
+		item.id = *account.Id
+		item.updates = accountImportUpdates(account, *row)
+		if len(item.updates) > 0 && admin {
+			item.Errors = append(item.Errors, "the admin account cannot be changed by an import")
+			item.Action = constant.ImportActionInvalid
+			item.updates = nil
+		} else if len(item.updates) > 0 {
+			item.Action = constant.ImportActionUpdate
+		} else {
+			item.Action = constant.ImportActionSkip
+		}
+	}
+	return nil
+}
+
+// accountImportUpdates the columns of the row that differ from the account
+func accountImportUpdates(account entity.Account, row dto.AccountImportDto) map[string]interface{} {
+	updates := map[string]interface{}{}
+	if row.Pass != nil {
+		if util.IsPassHash(*row.Pass) {
+			if *row.Pass != *account.Pass {
+				updates["pass"] = *row.Pass
+			}
+		} else if ok, _ := util.VerifyPassword(*account.Pass, *row.Pass); !ok {
+			updates["pass"] = *row.Pass
+		}
+	}
+	if row.ConPass != nil {
+		conPass := fmt.Sprintf("%s.%s", *account.Username, *row.ConPass)
+		if conPass != *account.ConPass {
+			updates["con_pass"] = conPass
+		}
+	}
+	int64Columns := []struct {
+		column string
+		value  *int64
+		old    *int64
+	}{
+		{"quota", row.Quota, account.Quota},
+		{"download", row.Download, account.Download},
+		{"upload", row.Upload, account.Upload},
+		{"expire_time", row.ExpireTime, account.ExpireTime},
+		{"device_no", row.DeviceNo, account.DeviceNo},
+		{"kick_util_time", row.KickUtilTime, account.KickUtilTime},
+		{"deleted", row.Deleted, account.Deleted},
+		{"plan_id", row.PlanId, account.PlanId},
+		{"reset_interval", row.ResetInterval, account.ResetInterval},
+	}
+	for _, item := range int64Columns {
+		if item.value != nil && (item.old == nil || *item.value != *item.old) {
+			updates[item.column] = *item.value
+		}
+	}
+	if row.Role != nil && *row.Role != *account.Role {
+		updates["role"] = *row.Role
+	}
+	if row.ResetCycle != nil && *row.ResetCycle != *account.ResetCycle {
+		updates["reset_cycle"] = *row.ResetCycle
+	}
+	if row.TrafficMultiplier != nil && *row.TrafficMultiplier != *account.TrafficMultiplier {
+		updates["traffic_multiplier"] = *row.TrafficMultiplier
+	}
+	if row.BillingMode != nil && *row.BillingMode != *account.BillingMode {
+		updates["billing_mode"] = *row.BillingMode
+	}
+	return updates
+}
+
+func commitAccountImport(rows []accountImportRow) error {
+	var creates []entity.Account
+	updates := map[int64]map[string]interface{}{}
+	for _, item := range rows {
+		switch item.Action {
+		case constant.ImportActionCreate:
+			row := item.row
+			pass, err := accountImportPass(*row.Pass)
+			if err != nil {
+				return err
+			}
+			conPass := fmt.Sprintf("%s.%s", *row.Username, *row.ConPass)
+			creates = append(creates, entity.Account{
+				Username:      row.Username,
+				Pass:          &pass,
+				ConPass:       &conPass,
+				Quota:         row.Quota,
+				Download:      row.Download,
+				Upload:        row.Upload,
+				ExpireTime:    row.ExpireTime,
+				DeviceNo:      row.DeviceNo,
+				KickUtilTime:  row.KickUtilTime,
+				Role:          row.Role,
+				Deleted:       row.Deleted,
+				PlanId:        row.PlanId,
+				ResetCycle:    row.ResetCycle,
+				ResetInterval: row.ResetInterval,
+
+				TrafficMultiplier: row.TrafficMultiplier,
+				BillingMode:       row.BillingMode,
+			})
+		case constant.ImportActionUpdate:
+			if pass, ok := item.updates["pass"]; ok {
+				hash, err := accountImportPass(pass.(string))
+				if err != nil {
+					return err
+				}
+				item.updates["pass"] = hash
+			}
+			updates[item.id] = item.updates
+		}
+	}
+	return dao.ImportAccount(creates, updates)
+}
+
+// accountImportPass keep the hash of an export, hash a plain text password
+func accountImportPass(pass string) (string, error) {
+	if util.IsPassHash(pass) {
+		return pass, nil
+	}
+	return util.HashPassword(pass)
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+
+	"h-ui/dao"
+	"h-ui/model/constant"
+	"h-ui/model/dto"
+	"h-ui/model/entity"
+)
+
+func TestParseAccountImportCsv(t *testing.T) {
+	rows, err := parseAccountImportCsv([]byte("\ufeffusername, quota ,download,expireTime\n" +
+		"user01,100, ,1700000000000\n" +
+		"user02,,5\n" +
+		"user03,1GiB,x,1.5\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 3", len(rows))
+	}
+	// the byte order mark is not part of the first column name
+	if row := rows[0].row; row.Username == nil || *row.Username != "user01" || row.Quota == nil || *row.Quota != 100 ||
+		row.Download != nil || row.ExpireTime == nil || *row.ExpireTime != 1700000000000 || len(rows[0].Errors) > 0 {
+		t.Errorf("row 1: got %+v %v", row, rows[0].Errors)
+	}
+	// the empty and missing cells leave the fields unchanged
+	if row := rows[1].row; row.Quota != nil || row.Download == nil || *row.Download != 5 || row.ExpireTime != nil || len(rows[1].Errors) > 0 {
+		t.Errorf("row 2: got %+v %v", row, rows[1].Errors)
+	}
+	want := []string{"quota is not a number", "download is not a number", "expireTime is not a number"}
+	if rows[2].Line != 4 || !reflect.DeepEqual(rows[2].Errors, want) {
+		t.Errorf("row 3: got line %d %v, want line 4 %v", rows[2].Line, rows[2].Errors, want)
+	}
+
+	if _, err = parseAccountImportCsv([]byte("name,quota\nuser01,1\n")); err == nil {
+		t.Error("a csv without the username column should fail")
+	}
+}
+
+func TestPreviewAccountImport(t *testing.T) {
+	initTestDb(t)
+	saveTestAccount(t, "user01", nil)
+	saveTestAccount(t, "user02", nil)
+	name := "monthly"
+	planId, err := dao.SavePlan(entity.Plan{Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := parseAccountImportCsv([]byte("username,quota,planId\n" +
+		"user01,-1,\n" +
+		"user02,100,\n" +
+		"user03,100,\n" +
+		"user04,,99\n" +
+		"user05,," + strconv.FormatInt(planId, 10) + "\n" +
+		"user02,200,\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var creates []string
+	err = previewAccountImport(rows, func(row dto.AccountImportDto, create bool) []string {
+		if create {
+			creates = append(creates, *row.Username)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		action string
+		errors []string
+	}{
+		{constant.ImportActionSkip, nil},
+		{constant.ImportActionUpdate, nil},
+		{constant.ImportActionCreate, nil},
+		{constant.ImportActionInvalid, []string{"plan 99 not exist"}},
+		{constant.ImportActionCreate, nil},
+		{constant.ImportActionInvalid, []string{"username is duplicated on line 3"}},
+	}
+	for i, item := range rows {
+		if item.Action != want[i].action || !reflect.DeepEqual(item.Errors, want[i].errors) {
+			t.Errorf("line %d: got %s %v, want %s %v", item.Line, item.Action, item.Errors, want[i].action, want[i].errors)
+		}
+	}
+	if updates := rows[1].updates; len(updates) != 1 || updates["quota"] != int64(100) {
+		t.Errorf("line 3: got the updates %v, want only the quota", updates)
+	}
+	if !reflect.DeepEqual(creates, []string{"user03", "user04", "user05"}) {
+		t.Errorf("got the rows validated as creates %v", creates)
+	}
+}
+
+func TestParseAccountImportCsvBilling(t *testing.T) {
+	rows, err := parseAccountImportCsv([]byte("username,trafficMultiplier,billingMode\nuser01,1.5,max\nuser02,,\nuser03,x,sum\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 3", len(rows))
+	}
+	if row := rows[0].row; row.TrafficMultiplier == nil || *row.TrafficMultiplier != 1.5 || row.BillingMode == nil || *row.BillingMode != "max" {
+		t.Fatalf("row 1: got %v %v, want 1.5 max", row.TrafficMultiplier, row.BillingMode)
+	}
+	if row := rows[1].row; row.TrafficMultiplier != nil || row.BillingMode != nil {
+		t.Fatalf("row 2: the empty cells must leave the fields unchanged")
+	}
+	if len(rows[2].Errors) != 1 || rows[2].Errors[0] != "trafficMultiplier is not a number" {
+		t.Fatalf("row 3: got %v, want trafficMultiplier is not a number", rows[2].Errors)
+	}
+}
+
+func TestPreviewAccountImportAdmin(t *testing.T) {
+	initTestDb(t)
+	saveTestAccount(t, "user01", nil)
+	tests := []struct {
+		name   string
+		csv    string
+		action string
+		errors []string
+	}{
+		{"the unchanged admin of an export", "sysadmin,-1,admin,0", constant.ImportActionSkip, nil},
+		{"a user made admin", "user01,,admin,", constant.ImportActionInvalid, []string{"role admin cannot be imported"}},
+		{"a new admin", "user09,,admin,", constant.ImportActionInvalid, []string{"role admin cannot be imported"}},
+		{"the admin made user", "sysadmin,,user,", constant.ImportActionInvalid, []string{"the admin account cannot be changed by an import"}},
+		{"the admin disabled", "sysadmin,,,1", constant.ImportActionInvalid, []string{"the admin account cannot be changed by an import"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := parseAccountImportCsv([]byte("username,quota,role,deleted\n" + tt.csv + "\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = previewAccountImport(rows, func(row dto.AccountImportDto, create bool) []string { return nil }); err != nil {
+				t.Fatal(err)
+			}
+			if rows[0].Action != tt.action || !reflect.DeepEqual(rows[0].Errors, tt.errors) || (tt.action == constant.ImportActionInvalid && rows[0].updates != nil) {
+				t.Errorf("got %s %v %v, want %s %v", rows[0].Action, rows[0].Errors, rows[0].updates, tt.action, tt.errors)
+			}
+		})
+	}
+}
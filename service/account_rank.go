@@ -0,0 +1,204 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"h-ui/dao"
+	"h-ui/model/constant"
+	"h-ui/model/dto"
+	"h-ui/model/entity"
+	"h-ui/model/vo"
+	"h-ui/util"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// accountRankTraffic the traffic of an account in the period and in the equally long period before
+type accountRankTraffic struct {
+	download         int64
+	upload           int64
+	previousDownload int64
+	previousUpload   int64
+}
+
+// RankAccount the accounts using the most traffic in the period, compared with the equally long period before.
+// The cycle is the traffic of every account since its last reset, the other periods are summed from the hourly buckets.
+func RankAccount(accountRankDto dto.AccountRankDto) (vo.AccountRankReportVo, error) {
+	report := vo.AccountRankReportVo{
+		Metric:   *accountRankDto.Metric,
+		Period:   *accountRankDto.Period,
+		Accounts: []vo.AccountRankVo{},
+	}
+	var limit int64 = 10
+	if accountRankDto.Limit != nil {
+		limit = *accountRankDto.Limit
+	}
+
+	accounts, err := dao.ListAccount("trashed_at = 0")
+	if err != nil {
+		return report, err
+	}
+	var traffics map[int64]*accountRankTraffic
+	now := time.Now()
+	if *accountRankDto.Period == constant.RankPeriodCycle {
+		report.EndTime = now.UnixMilli()
+		if traffics, err = rankAccountCycle(accounts, now); err != nil {
+			return report, err
+		}
+	} else {
+		var start, end time.Time
+		switch *accountRankDto.Period {
+		case constant.RankPeriodToday:
+			start = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+			end = now
+		case constant.RankPeriodWeek:
+			start = now.Add(-7 * 24 * time.Hour)
+			end = now
+		case constant.RankPeriodCustom:
+			start = time.UnixMilli(*accountRankDto.StartTime)
+			end = time.UnixMilli(*accountRankDto.EndTime)
+		default:
+			return report, errors.New("unsupported period")
+		}
+		// today is compared with yesterday until the same time
+		previousStart := start.Add(-end.Sub(start))
+		if *accountRankDto.Period == constant.RankPeriodToday {
+			previousStart = start.AddDate(0, 0, -1)
+		}
+		report.StartTime = start.UnixMilli()
+		report.EndTime = end.UnixMilli()
+		if traffics, err = rankAccountRange(start, end, previousStart); err != nil {
+			return report, err
+		}
+	}
+
+	metric := func(download int64, upload int64) int64 {
+		switch *accountRankDto.Metric {
+		case constant.RankMetricDownload:
+			return download
+		case constant.RankMetricUpload:
+			return upload
+		default:
+			return download + upload
+		}
+	}
+	for _, item := range accounts {
+		traffic, exist := traffics[*item.Id]
+		if !exist {
+			continue
+		}
+		accountRankVo := vo.AccountRankVo{
+			AccountId:     *item.Id,
+			Username:      *item.Username,
+			Download:      traffic.download,
+			Upload:        traffic.upload,
+			Value:         metric(traffic.download, traffic.upload),
+			PreviousValue: metric(traffic.previousDownload, traffic.previousUpload),
+		}
+		if accountRankVo.PreviousValue > 0 {
+			change := float64(accountRankVo.Value-accountRankVo.PreviousValue) / float64(accountRankVo.PreviousValue) * 100
+			accountRankVo.Change = &change
+		}
+		report.Total += accountRankVo.Value
+		report.PreviousTotal += accountRankVo.PreviousValue
+		if accountRankVo.Value > 0 {
+			report.Accounts = append(report.Accounts, accountRankVo)
+		}
+	}
+
+	sort.Slice(report.Accounts, func(i, j int) bool {
+		if report.Accounts[i].Value != report.Accounts[j].Value {
+			return report.Accounts[i].Value > report.Accounts[j].Value
+		}
+		return report.Accounts[i].Username < report.Accounts[j].Username
+	})
+	if int64(len(report.Accounts)) > limit {
+		report.Accounts = report.Accounts[:limit]
+	}
+	for i := range report.Accounts {
+		report.Accounts[i].Rank = int64(i + 1)
+		report.Accounts[i].Share = float64(report.Accounts[i].Value) / float64(report.Total) * 100
+	}
+	return report, nil
+}
+
+// rankAccountRange the traffic of the hourly buckets starting in the range and in the equally long range from previousStart
+func rankAccountRange(start time.Time, end time.Time, previousStart time.Time) (map[int64]*accountRankTraffic, error) {
+	traffics := map[int64]*accountRankTraffic{}
+	current, err := dao.SumAccountTraffic("period = ? and period_time >= ? and period_time < ?",
+		constant.TrafficPeriodHour, start.UnixMilli(), end.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range current {
+		traffics[*item.AccountId] = &accountRankTraffic{download: *item.Download, upload: *item.Upload}
+	}
+	previous, err := dao.SumAccountTraffic("period = ? and period_time >= ? and period_time < ?",
+		constant.TrafficPeriodHour, previousStart.UnixMilli(), previousStart.Add(end.Sub(start)).UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range previous {
+		traffic, exist := traffics[*item.AccountId]
+		if !exist {
+			traffic = &accountRankTraffic{}
+			traffics[*item.AccountId] = traffic
+		}
+		traffic.previousDownload = *item.Download
+		traffic.previousUpload = *item.Upload
+	}
+	return traffics, nil
+}
+
+// rankAccountCycle the traffic of every account since its last reset, and of the equally long period before the reset
+// from the hourly buckets, the accounts reset at the same time are summed together
+func rankAccountCycle(accounts []entity.Account, now time.Time) (map[int64]*accountRankTraffic, error) {
+	traffics := map[int64]*accountRankTraffic{}
+	cycles := map[int64][]int64{}
+	for _, item := range accounts {
+		traffics[*item.Id] = &accountRankTraffic{download: *item.Download, upload: *item.Upload}
+		start := item.CreateTime.UnixMilli()
+		if *item.ResetAt > 0 {
+			start = *item.ResetAt
+		}
+		cycles[start] = append(cycles[start], *item.Id)
+	}
+	for start, ids := range cycles {
+		previousStart := 2*start - now.UnixMilli()
+		for _, idList := range util.SplitArr(ids, 500) {
+			previous, err := dao.SumAccountTraffic("account_id in ? and period = ? and period_time >= ? and period_time < ?",
+				idList, constant.TrafficPeriodHour, previousStart, start)
+			if err != nil {
+				return nil, err
+			}
+			for _, item := range previous {
+				traffics[*item.AccountId].previousDownload = *item.Download
+				traffics[*item.AccountId].previousUpload = *item.Upload
+			}
+		}
+	}
+	return traffics, nil
+}
+
+// AccountRankCsv the rows of the report with a header, the value is of the ranked metric
+func AccountRankCsv(report vo.AccountRankReportVo) [][]string {
+	records := [][]string{{"rank", "username", "download", "upload", "value", "share", "previous", "change"}}
+	for _, item := range report.Accounts {
+		change := ""
+		if item.Change != nil {
+			change = fmt.Sprintf("%.2f", *item.Change)
+		}
+		records = append(records, []string{
+			strconv.FormatInt(item.Rank, 10),
+			item.Username,
+			strconv.FormatInt(item.Download, 10),
+			strconv.FormatInt(item.Upload, 10),
+			strconv.FormatInt(item.Value, 10),
+			fmt.Sprintf("%.2f", item.Share),
+			strconv.FormatInt(item.PreviousValue, 10),
+			change,
+		})
+	}
+	return records
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"h-ui/dao"
+	"h-ui/model/constant"
+	"h-ui/model/dto"
+	"h-ui/model/entity"
+	"h-ui/model/vo"
+)
+
+func upsertTestTraffic(t *testing.T, username string, periodTime time.Time, download int64, upload int64) {
+	if err := dao.UpsertAccountTraffic(username, constant.TrafficPeriodHour, periodTime.UnixMilli(), download, upload); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRankAccount(t *testing.T) {
+	initTestDb(t)
+	for _, username := range []string{"user01", "user02", "user03", "user04"} {
+		saveTestAccount(t, username, nil)
+	}
+	start := time.Date(2026, 1, 10, 0, 0, 0, 0, time.Local)
+	end := start.Add(2 * time.Hour)
+	// the period and the equally long period before
+	upsertTestTraffic(t, "user01", start, 100, 10)
+	upsertTestTraffic(t, "user01", start.Add(-time.Hour), 50, 5)
+	upsertTestTraffic(t, "user02", start.Add(time.Hour), 50, 50)
+	upsertTestTraffic(t, "user03", start, 30, 0)
+	upsertTestTraffic(t, "user03", start.Add(-2*time.Hour), 60, 0)
+	upsertTestTraffic(t, "user04", start.Add(-time.Hour), 40, 0)
+	// outside of both periods
+	upsertTestTraffic(t, "user02", end, 1000, 0)
+	upsertTestTraffic(t, "user04", start.Add(-3*time.Hour), 1000, 0)
+
+	metric, period := constant.RankMetricTotal, constant.RankPeriodCustom
+	startTime, endTime := start.UnixMilli(), end.UnixMilli()
+	var limit int64 = 2
+	report, err := RankAccount(dto.AccountRankDto{Metric: &metric, Period: &period, StartTime: &startTime, EndTime: &endTime, Limit: &limit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Total != 240 || report.PreviousTotal != 155 {
+		t.Fatalf("total %d previous total %d, want 240 155", report.Total, report.PreviousTotal)
+	}
+
+	// the top 2 of user01 110, user02 100 and user03 30, the share is of the total of every account
+	if len(report.Accounts) != 2 {
+		t.Fatalf("got %d accounts, want 2", len(report.Accounts))
+	}
+	first, second := report.Accounts[0], report.Accounts[1]
+	if first.Rank != 1 || first.Username != "user01" || first.Value != 110 || first.PreviousValue != 55 {
+		t.Fatalf("first %+v, want user01 110 55", first)
+	}
+	if second.Rank != 2 || second.Username != "user02" || second.Value != 100 || second.PreviousValue != 0 {
+		t.Fatalf("second %+v, want user02 100 0", second)
+	}
+	if math.Abs(first.Share-110.0/240*100) > 1e-9 || math.Abs(second.Share-100.0/240*100) > 1e-9 {
+		t.Fatalf("share %f %f, want %f %f", first.Share, second.Share, 110.0/240*100, 100.0/240*100)
+	}
+	if first.Change == nil || math.Abs(*first.Change-100) > 1e-9 {
+		t.Fatalf("change of user01 %v, want 100", first.Change)
+	}
+	// nothing to compare with
+	if second.Change != nil {
+		t.Fatalf("change of user02 %v, want nil", *second.Change)
+	}
+
+	limit = 10
+	metric = constant.RankMetricDownload
+	if report, err = RankAccount(dto.AccountRankDto{Metric: &metric, Period: &period, StartTime: &startTime, EndTime: &endTime, Limit: &limit}); err != nil {
+		t.Fatal(err)
+	}
+	var usernames []string
+	for _, item := range report.Accounts {
+		usernames = append(usernames, item.Username)
+	}
+	// equal downloads are ordered by username, user04 has no traffic in the period
+	if want := []string{"user01", "user02", "user03"}; !reflect.DeepEqual(usernames, want) {
+		t.Fatalf("got %v, want %v", usernames, want)
+	}
+	if third := report.Accounts[2]; third.Change == nil || math.Abs(*third.Change+50) > 1e-9 {
+		t.Fatalf("change of user03 %v, want -50", third.Change)
+	}
+}
+
+func TestRankAccountCycle(t *testing.T) {
+	initTestDb(t)
+	id := saveTestAccount(t, "user01", nil)
+	if err := ResetTraffic(id); err != nil {
+		t.Fatal(err)
+	}
+	account, err := dao.GetAccount("id = ?", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *account.ResetAt == 0 {
+		t.Fatal("the reset does not record reset_at")
+	}
+
+	now := time.Now()
+	resetAt := now.Add(-2 * time.Hour).Truncate(time.Hour)
+	if err = dao.UpdateAccount([]int64{id}, map[string]interface{}{"reset_at": resetAt.UnixMilli(), "download": 70, "upload": 7}); err != nil {
+		t.Fatal(err)
+	}
+	cycle := now.Sub(resetAt)
+	upsertTestTraffic(t, "user01", resetAt.Add(-time.Hour), 40, 4)
+	// before the previous cycle
+	upsertTestTraffic(t, "user01", resetAt.Add(-cycle-time.Hour), 1000, 0)
+	if account, err = dao.GetAccount("id = ?", id); err != nil {
+		t.Fatal(err)
+	}
+
+	traffics, err := rankAccountCycle([]entity.Account{account}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := accountRankTraffic{download: 70, upload: 7, previousDownload: 40, previousUpload: 4}
+	if traffics[id] == nil || *traffics[id] != want {
+		t.Fatalf("got %+v, want %+v", traffics[id], want)
+	}
+}
+
+func TestAccountRankCsv(t *testing.T) {
+	change := -12.345
+	report := vo.AccountRankReportVo{Accounts: []vo.AccountRankVo{
+		{Rank: 1, Username: "user01", Download: 100, Upload: 10, Value: 110, Share: 52.3812, PreviousValue: 125, Change: &change},
+		{Rank: 2, Username: "user02", Download: 50, Upload: 50, Value: 100, Share: 47.6188},
+	}}
+	want := [][]string{
+		{"rank", "username", "download", "upload", "value", "share", "previous", "change"},
+		{"1", "user01", "100", "10", "110", "52.38", "125", "-12.35"},
+		{"2", "user02", "50", "50", "100", "47.62", "0", ""},
+	}
+	if got := AccountRankCsv(report); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"errors"
+	"h-ui/dao"
+	"h-ui/model/bo"
+	"h-ui/model/constant"
+	"h-ui/model/dto"
+	"time"
+)
+
+func ListAccountTagCount() ([]bo.AccountTagCount, error) {
+	return dao.ListAccountTagCount()
+}
+
+// ListAccountTags the tags of every account
+func ListAccountTags(ids []int64) (map[int64][]string, error) {
+	accountTags, err := dao.ListAccountTag("account_id in ?", ids)
+	if err != nil {
+		return nil, err
+	}
+	tags := map[int64][]string{}
+	for _, item := range accountTags {
+		tags[*item.AccountId] = append(tags[*item.AccountId], *item.Tag)
+	}
+	return tags, nil
+}
+
+func SaveAccountTag(ids []int64, tags []string) error {
+	return dao.SaveAccountTag(ids, tags)
+}
+
+func DeleteAccountTag(ids []int64, tags []string) error {
+	return dao.DeleteAccountTag("account_id in ? and tag in ?", ids, tags)
+}
+
+// AccountTagAction apply the action to every account of the tag in one transaction, the updated accounts are kicked
+// after it is committed
+func AccountTagAction(accountTagActionDto dto.AccountTagActionDto) error {
+	tag := *accountTagActionDto.Tag
+	kick := false
+	var usernames []string
+	var err error
+	switch *accountTagActionDto.Action {
+	case constant.TagActionKick:
+		if !Hysteria2AnyRunning() {
+			return errors.New("hysteria2 is not running")
+		}
+		usernames, err = dao.UpdateTagAccount(tag, map[string]interface{}{"kick_util_time": *accountTagActionDto.KickUtilTime})
+		kick = true
+	case constant.TagActionResetTraffic:
+		usernames, err = dao.UpdateTagAccount(tag, map[string]interface{}{"download": 0, "upload": 0, "reset_at": time.Now().UnixMilli()})
+	case constant.TagActionExtendExpire:
+		usernames, err = dao.ExtendTagAccountExpire(tag, time.Now().UnixMilli(), *accountTagActionDto.Days*24*int64(time.Hour/time.Millisecond))
+	case constant.TagActionDeviceNo:
+		usernames, err = dao.UpdateTagAccount(tag, map[string]interface{}{"device_no": *accountTagActionDto.DeviceNo})
+	case constant.TagActionDisable:
+		usernames, err = dao.UpdateTagAccount(tag, map[string]interface{}{"deleted": 1})
+		kick = Hysteria2AnyRunning()
+	case constant.TagActionEnable:
+		usernames, err = dao.UpdateTagAccount(tag, map[string]interface{}{"deleted": 0})
+	default:
+		return errors.New("action not supported")
+	}
+	if err != nil || !kick || len(usernames) == 0 {
+		return err
+	}
+	return kickUsers(usernames)
+}
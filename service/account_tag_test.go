@@ -0,0 +1,79 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"h-ui/dao"
+	"h-ui/model/constant"
+	"h-ui/model/dto"
+)
+
+func TestAccountTagAction(t *testing.T) {
+	initTestDb(t)
+	tagged := saveTestAccount(t, "tagged", map[string]interface{}{"download": 10, "upload": 1})
+	trashed := saveTestAccount(t, "trashed", map[string]interface{}{"download": 10, "upload": 1, "trashed_at": time.Now().UnixMilli()})
+	untagged := saveTestAccount(t, "untagged", map[string]interface{}{"download": 10, "upload": 1})
+	if err := dao.SaveAccountTag([]int64{tagged, trashed}, []string{"vip"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := dao.SaveAccountTag([]int64{trashed}, []string{"old"}); err != nil {
+		t.Fatal(err)
+	}
+
+	counts, err := ListAccountTagCount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(counts) != 1 || counts[0].Tag != "vip" || counts[0].Count != 1 {
+		t.Errorf("got tag counts %v, want only vip with the account that is not trashed", counts)
+	}
+
+	tag, action := "vip", constant.TagActionResetTraffic
+	if err = AccountTagAction(dto.AccountTagActionDto{Tag: &tag, Action: &action}); err != nil {
+		t.Fatal(err)
+	}
+	for id, reset := range map[int64]bool{tagged: true, trashed: false, untagged: false} {
+		account, err := dao.GetAccount("id = ?", id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (*account.Download == 0 && *account.ResetAt > 0) != reset {
+			t.Errorf("account %s: download %d reset_at %d, reset %v", *account.Username, *account.Download, *account.ResetAt, reset)
+		}
+	}
+}
+
+func TestAccountTagActionAdmin(t *testing.T) {
+	initTestDb(t)
+	user := saveTestAccount(t, "user01", nil)
+	admin, err := dao.GetAccount("role = 'admin'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = dao.SaveAccountTag([]int64{*admin.Id, user}, []string{"vip"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tag := "vip"
+	var days, deviceNo int64 = 1, 1
+	for _, action := range []string{constant.TagActionDisable, constant.TagActionExtendExpire, constant.TagActionDeviceNo} {
+		if err = AccountTagAction(dto.AccountTagActionDto{Tag: &tag, Action: &action, Days: &days, DeviceNo: &deviceNo}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	account, err := dao.GetAccount("id = ?", *admin.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *account.Deleted != 0 || *account.ExpireTime != *admin.ExpireTime || *account.DeviceNo != *admin.DeviceNo {
+		t.Errorf("the tagged admin was changed by the tag actions: deleted %d expire_time %d device_no %d",
+			*account.Deleted, *account.ExpireTime, *account.DeviceNo)
+	}
+	if account, err = dao.GetAccount("id = ?", user); err != nil {
+		t.Fatal(err)
+	}
+	if *account.Deleted != 1 || *account.DeviceNo != 1 {
+		t.Errorf("the tagged user was not changed: deleted %d device_no %d", *account.Deleted, *account.DeviceNo)
+	}
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"h-ui/dao"
+	"h-ui/model/entity"
+	"h-ui/util"
+)
+
+func TestPurgeAccount(t *testing.T) {
+	initTestDb(t)
+	trashed := saveTestAccount(t, "trashed", map[string]interface{}{"trashed_at": time.Now().UnixMilli()})
+	kept := saveTestAccount(t, "kept", nil)
+	ids := []int64{trashed, kept}
+	if err := dao.SaveAccountTag(ids, []string{"vip"}); err != nil {
+		t.Fatal(err)
+	}
+	kind := "quota"
+	var threshold int64 = 80
+	if err := dao.SaveAccountAlert([]entity.AccountAlert{
+		{AccountId: &trashed, Kind: &kind, Threshold: &threshold},
+		{AccountId: &kept, Kind: &kind, Threshold: &threshold},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	upsertTestTraffic(t, "trashed", time.Now().Truncate(time.Hour), 10, 1)
+	upsertTestTraffic(t, "kept", time.Now().Truncate(time.Hour), 10, 1)
+
+	// only the trashed account is purged
+	if err := PurgeAccount(ids); err != nil {
+		t.Fatal(err)
+	}
+	accounts, err := dao.ListAccount("id in ?", ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 1 || *accounts[0].Id != kept {
+		t.Fatalf("got %d accounts after the purge, want only the account that is not trashed", len(accounts))
+	}
+	tags, err := dao.ListAccountTag("account_id in ?", ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alerts, err := dao.ListAccountAlert("account_id in ?", ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	traffic, err := dao.SumAccountTraffic("account_id in ?", ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || *tags[0].AccountId != kept || len(alerts) != 1 || *alerts[0].AccountId != kept ||
+		len(traffic) != 1 || *traffic[0].AccountId != kept {
+		t.Errorf("tags %d alerts %d traffic %d left, want only those of the kept account", len(tags), len(alerts), len(traffic))
+	}
+}
+
+func TestUserLoginAt(t *testing.T) {
+	initTestDb(t)
+	pass, err := util.HashPassword("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := saveTestAccount(t, "user01", map[string]interface{}{"pass": pass})
+
+	if _, err = UserLogin("user01", "wrong"); err == nil {
+		t.Fatal("a wrong password should not log in")
+	}
+	account, err := dao.GetAccount("id = ?", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *account.LoginAt != 0 {
+		t.Errorf("a failed login set login_at to %d", *account.LoginAt)
+	}
+
+	if _, err = UserLogin("user01", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if account, err = dao.GetAccount("id = ?", id); err != nil {
+		t.Fatal(err)
+	}
+	if *account.LoginAt == 0 {
+		t.Error("login_at is not set by the login")
+	}
+}
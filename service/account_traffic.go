@@ -0,0 +1,54 @@
+package service
+
+import (
+	"github.com/sirupsen/logrus"
+	"h-ui/dao"
+	"h-ui/model/constant"
+	"h-ui/model/dto"
+	"h-ui/model/entity"
+	"strconv"
+	"time"
+)
+
+// saveAccountTrafficHistory record the traffic delta into the hourly and daily buckets
+func saveAccountTrafficHistory(username string, download int64, upload int64) {
+	now := time.Now()
+	hourTime := time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 0, 0, 0, now.Location()).UnixMilli()
+	dayTime := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).UnixMilli()
+	if err := dao.UpsertAccountTraffic(username, constant.TrafficPeriodHour, hourTime, download, upload); err != nil {
+		return
+	}
+	_ = dao.UpsertAccountTraffic(username, constant.TrafficPeriodDay, dayTime, download, upload)
+}
+
+func ListAccountTraffic(accountTrafficDto dto.AccountTrafficDto) ([]entity.AccountTraffic, error) {
+	if accountTrafficDto.AccountId != nil {
+		return dao.ListAccountTraffic("account_id = ? and period = ? and period_time between ? and ?",
+			*accountTrafficDto.AccountId,
+			*accountTrafficDto.Period,
+			*accountTrafficDto.StartTime,
+			*accountTrafficDto.EndTime)
+	}
+	return dao.ListAccountTraffic("period = ? and period_time between ? and ?",
+		*accountTrafficDto.Period,
+		*accountTrafficDto.StartTime,
+		*accountTrafficDto.EndTime)
+}
+
+// CronPurgeAccountTraffic delete the traffic history older than the retention days, 0 keeps it forever
+func CronPurgeAccountTraffic() {
+	retention, err := dao.GetConfig("key = ?", constant.AccountTrafficRetention)
+	if err != nil {
+		return
+	}
+	days, err := strconv.ParseInt(*retention.Value, 10, 64)
+	if err != nil {
+		logrus.Errorf("account traffic retention string conv int64 err: %v", err)
+		return
+	}
+	if days <= 0 {
+		return
+	}
+	before := time.Now().AddDate(0, 0, -int(days)).UnixMilli()
+	_ = dao.DeleteAccountTraffic("period_time < ?", before)
+}
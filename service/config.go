@@ -8,7 +8,6 @@ import (
 	"h-ui/model/constant"
 	"h-ui/model/entity"
 	"strconv"
-	"strings"
 
 	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
@@ -62,7 +61,19 @@ func GetHysteria2Config() (bo.Hysteria2ServerConfig, error) {
 	return serverConfig, nil
 }
 
-func UpdateHysteria2Config(hysteria2ServerConfig bo.Hysteria2ServerConfig) error {
+func UpdateHysteria2Config(hysteria2ServerConfig bo.Hysteria2ServerConfig, operator string) error {
+	if err := setHysteria2Auth(&hysteria2ServerConfig); err != nil {
+		return err
+	}
+	yamlConfig, err := yaml.Marshal(&hysteria2ServerConfig)
+	if err != nil {
+		return err
+	}
+	return applyHysteria2Config(string(yamlConfig), operator, "")
+}
+
+// setHysteria2Auth the users are authenticated by the panel and the traffic stats api is protected by the jwt secret
+func setHysteria2Auth(hysteria2ServerConfig *bo.Hysteria2ServerConfig) error {
 	// 默认值
 	config, err := dao.ListConfig("key in ?", []string{constant.HUIWebPort, constant.JwtSecret})
 	if err != nil {
@@ -98,46 +109,25 @@ func UpdateHysteria2Config(hysteria2ServerConfig bo.Hysteria2ServerConfig) error
 	http.Insecure = &authHttpInsecure
 	auth.HTTP = &http
 	hysteria2ServerConfig.Auth = &auth
-	hysteria2ServerConfig.TrafficStats.Secret = &jwtSecret
-
-	yamlConfig, err := yaml.Marshal(&hysteria2ServerConfig)
-	if err != nil {
-		return err
+	if hysteria2ServerConfig.TrafficStats == nil {
+		hysteria2ServerConfig.TrafficStats = &bo.ServerConfigTrafficStats{}
 	}
-	return dao.UpdateConfig([]string{constant.Hysteria2Config}, map[string]interface{}{"value": string(yamlConfig)})
+	hysteria2ServerConfig.TrafficStats.Secret = &jwtSecret
+	return nil
 }
 
-func SetHysteria2Config(hysteria2ServerConfig bo.Hysteria2ServerConfig) error {
+func SetHysteria2Config(hysteria2ServerConfig bo.Hysteria2ServerConfig, operator string) error {
 	config, err := yaml.Marshal(&hysteria2ServerConfig)
 	if err != nil {
 		return err
 	}
-	return dao.UpdateConfig([]string{constant.Hysteria2Config}, map[string]interface{}{"value": string(config)})
+	return applyHysteria2Config(string(config), operator, "")
 }
 
 func UpsertConfig(configs []entity.Config) error {
 	return dao.UpsertConfig(configs)
 }
 
-func GetHysteria2ApiPort() (int64, error) {
-	hysteria2Config, err := GetHysteria2Config()
-	if err != nil {
-		return 0, err
-	}
-	if hysteria2Config.TrafficStats == nil || hysteria2Config.TrafficStats.Listen == nil {
-		errMsg := "hysteria2 Traffic Stats API (HTTP) Listen is nil"
-		logrus.Errorf(errMsg)
-		return 0, errors.New(errMsg)
-	}
-	apiPort, err := strconv.ParseInt(strings.Split(*hysteria2Config.TrafficStats.Listen, ":")[1], 10, 64)
-	if err != nil {
-		errMsg := fmt.Sprintf("apiPort: %s is invalid", *hysteria2Config.TrafficStats.Listen)
-		logrus.Errorf(errMsg)
-		return 0, errors.New(errMsg)
-	}
-	return apiPort, nil
-}
-
 func GetPortAndCert() (int64, string, string, error) {
 	configs, err := dao.ListConfig("key in ?", []string{constant.HUIWebPort, constant.HUICrtPath, constant.HUIKeyPath})
 	if err != nil {
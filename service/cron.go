@@ -1,48 +1,62 @@
 package service
 
 import (
+	"errors"
+	"fmt"
 	"github.com/sirupsen/logrus"
 	"h-ui/dao"
 	"h-ui/model/bo"
 	"h-ui/model/constant"
-	"h-ui/proxy"
+	"h-ui/model/entity"
 	"h-ui/util"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 var trafficMutex sync.Mutex
 var kickMutex sync.Mutex
 
+// trafficFlushTimeout how long a flush waits for the running sync before giving up
+const trafficFlushTimeout = 10 * time.Second
+
 func CronHandleAccount() {
 	go func() {
-		hysteriaEnable, err := dao.GetConfig("key = ?", constant.Hysteria2Enable)
-		if err != nil {
+		defer recoverCron("CronHandleAccount")
+		if !Hysteria2AnyRunning() {
 			return
 		}
-		if hysteriaEnable.Value != nil && *hysteriaEnable.Value == "1" {
-			apiPort, err := GetHysteria2ApiPort()
-			if err != nil {
-				return
-			}
-
-			jwtSecretConfig, err := dao.GetConfig("key = ?", constant.JwtSecret)
-			if err != nil {
-				return
-			}
-
-			// 保存流量数据
-			go saveAccountTraffic(apiPort, *jwtSecretConfig.Value)
-
-			// 踢下线
-			go kickAccount(apiPort, *jwtSecretConfig.Value)
+
+		jwtSecretConfig, err := dao.GetConfig("key = ?", constant.JwtSecret)
+		if err != nil {
+			return
 		}
+
+		// 保存流量数据
+		go func() {
+			defer recoverCron("saveAccountTraffic")
+			saveAccountTraffic(*jwtSecretConfig.Value)
+		}()
+
+		// 踢下线
+		go func() {
+			defer recoverCron("kickAccount")
+			kickAccount(*jwtSecretConfig.Value)
+		}()
 	}()
 }
 
+// recoverCron log the panic of a goroutine a cron job started, cron.Recover only covers the goroutine of the job itself
+func recoverCron(name string) {
+	if r := recover(); r != nil {
+		logrus.Errorf("Recovered from panic in %s: %v", name, r)
+	}
+}
+
+// CronResetTraffic the global reset, only for the accounts without a reset cycle of their own
 func CronResetTraffic() {
-	accounts, err := dao.ListAccount(nil, nil)
+	accounts, err := dao.ListAccount("reset_cycle = ? and trashed_at = 0", constant.ResetCycleGlobal)
 	if err != nil {
 		return
 	}
@@ -50,34 +64,101 @@ func CronResetTraffic() {
 	for _, item := range accounts {
 		ids = append(ids, *item.Id)
 	}
+	now := time.Now().UnixMilli()
 	idsList := util.SplitArr(ids, 100)
 	for _, item := range idsList {
-		if err := dao.UpdateAccount(item, map[string]interface{}{"download": 0, "upload": 0}); err != nil {
+		if err := dao.UpdateAccount(item, map[string]interface{}{"download": 0, "upload": 0, "reset_at": now}); err != nil {
+			continue
+		}
+	}
+}
+
+// CronResetTrafficCycle reset the accounts whose own reset cycle is due
+func CronResetTrafficCycle() {
+	accounts, err := dao.ListAccount("reset_cycle in ? and trashed_at = 0", []string{constant.ResetCycleMonthly, constant.ResetCycleDays})
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	for _, item := range accounts {
+		anchor := *item.CreateTime
+		if *item.ResetAnchor > 0 {
+			anchor = time.UnixMilli(*item.ResetAnchor)
+		}
+		// a reset before the anchor belongs to the previous plan, the cycle starts at the anchor
+		last := anchor
+		if *item.ResetAt > anchor.UnixMilli() {
+			last = time.UnixMilli(*item.ResetAt)
+		}
+		next := util.NextResetTime(*item.ResetCycle, *item.ResetInterval, anchor.In(now.Location()), last.In(now.Location()))
+		if next.IsZero() || now.Before(next) {
+			continue
+		}
+		if err := dao.UpdateAccount([]int64{*item.Id}, map[string]interface{}{"download": 0, "upload": 0, "reset_at": now.UnixMilli()}); err != nil {
 			continue
 		}
 	}
 }
 
-func saveAccountTraffic(apiPort int64, jwtSecret string) {
+// saveAccountTraffic skipped while another sync is running, the next run picks the traffic up
+func saveAccountTraffic(jwtSecret string) {
 	if !trafficMutex.TryLock() {
 		return
 	}
 	defer trafficMutex.Unlock()
+	syncAccountTraffic(jwtSecret)
+}
+
+// flushAccountTraffic save the traffic before an instance is stopped, its counters are lost with it so a running sync
+// is waited for instead of skipped
+func flushAccountTraffic(jwtSecret string) error {
+	deadline := time.Now().Add(trafficFlushTimeout)
+	for !trafficMutex.TryLock() {
+		if time.Now().After(deadline) {
+			logrus.Errorf("flush account traffic err: the running sync did not finish within %s", trafficFlushTimeout)
+			return errors.New("flush account traffic err")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	defer trafficMutex.Unlock()
+	syncAccountTraffic(jwtSecret)
+	return nil
+}
+
+// syncAccountTraffic the traffic of a user is summed over every running instance
+func syncAccountTraffic(jwtSecret string) {
+	start := time.Now()
+	var syncErrors int64
+	defer func() {
+		recordTrafficSync(time.Since(start), syncErrors)
+	}()
 
 	hysteria2TrafficTime, err := dao.GetConfig("key = ?", constant.Hysteria2TrafficTime)
 	if err != nil {
+		syncErrors++
 		return
 	}
 	hysteria2TrafficTimeFloat, err := strconv.ParseFloat(*hysteria2TrafficTime.Value, 64)
 	if err != nil {
 		logrus.Errorf("hysteria2TrafficTime string conv int64 err: %v", err)
+		syncErrors++
+		return
+	}
+
+	// the multiplier of the account, then of its plan, overrides HYSTERIA2_TRAFFIC_TIME
+	trafficMultipliers, err := dao.ListTrafficMultiplier()
+	if err != nil {
+		syncErrors++
 		return
 	}
 
-	users, err := proxy.NewHysteria2Api(apiPort).ListUsers(true, jwtSecret)
+	apis, skipped, err := listRunningHysteria2Api()
 	if err != nil {
+		syncErrors++
 		return
 	}
+	users, failed := listHysteria2UserTraffic(apis, jwtSecret)
+	syncErrors += int64(skipped + failed)
 	if len(users) > 0 {
 		userLists := util.SplitMap(users, 10)
 		var wg sync.WaitGroup
@@ -86,23 +167,61 @@ func saveAccountTraffic(apiPort int64, jwtSecret string) {
 			go func(userList map[string]bo.Hysteria2UserTraffic) {
 				defer wg.Done()
 				for username, traffic := range userList {
-					if err = dao.UpdateAccountTraffic(username, int64(float64(traffic.Rx)*hysteria2TrafficTimeFloat), int64(float64(traffic.Tx)*hysteria2TrafficTimeFloat)); err != nil {
+					trafficTime := hysteria2TrafficTimeFloat
+					if multiplier, exist := trafficMultipliers[username]; exist {
+						trafficTime = multiplier
+					}
+					download := int64(float64(traffic.Rx) * trafficTime)
+					upload := int64(float64(traffic.Tx) * trafficTime)
+					if err := dao.UpdateAccountTraffic(username, download, upload); err != nil {
+						atomic.AddInt64(&syncErrors, 1)
 						continue
 					}
+					saveAccountTrafficHistory(username, download, upload)
 				}
 			}(userList)
 		}
 		wg.Wait()
 	}
+
+	alertAccountThreshold()
+}
+
+// listHysteria2UserTraffic the traffic of a user summed over the instances, an instance whose api fails is skipped and
+// counted, the traffic of the others is still saved
+func listHysteria2UserTraffic(apis []hysteria2InstanceApi, jwtSecret string) (map[string]bo.Hysteria2UserTraffic, int) {
+	users := map[string]bo.Hysteria2UserTraffic{}
+	failed := 0
+	for _, item := range apis {
+		instanceUsers, err := item.api.ListUsers(true, jwtSecret)
+		if err != nil {
+			logrus.Errorf("%s list users err: %v", item.name, err)
+			failed++
+			continue
+		}
+		for username, traffic := range instanceUsers {
+			total := users[username]
+			total.Tx += traffic.Tx
+			total.Rx += traffic.Rx
+			users[username] = total
+		}
+	}
+	return users, failed
+}
+
+// listKickAccount the accounts of the usernames that must be kicked, every condition is limited to the usernames
+func listKickAccount(usernames []string, now int64) ([]entity.Account, error) {
+	return dao.ListAccount(fmt.Sprintf("username in ? and (deleted = 1 or trashed_at > 0 or (quota > 0 and quota < %s) or ? > expire_time or ? < kick_util_time)", dao.AccountBilledTraffic), usernames, now, now)
 }
 
-func kickAccount(apiPort int64, jwtSecret string) {
+// kickAccount the users are kicked from every running instance
+func kickAccount(jwtSecret string) {
 	if !kickMutex.TryLock() {
 		return
 	}
 	defer kickMutex.Unlock()
 
-	users, err := proxy.NewHysteria2Api(apiPort).OnlineUsers(jwtSecret)
+	users, err := hysteria2Online(jwtSecret)
 	if err != nil {
 		return
 	}
@@ -119,8 +238,7 @@ func kickAccount(apiPort int64, jwtSecret string) {
 			wg.Add(1)
 			go func(usernameList []string) {
 				defer wg.Done()
-				now := time.Now().UnixMilli()
-				accounts, err := dao.ListAccount("username in ? and (deleted = 1 or (quota > 0 and quota < download + upload)) or ? > expire_time or ? < kick_util_time", usernameList, now, now)
+				accounts, err := listKickAccount(usernameList, time.Now().UnixMilli())
 				if err != nil {
 					return
 				}
@@ -130,7 +248,7 @@ func kickAccount(apiPort int64, jwtSecret string) {
 					kickUsernames[j] = *item.Username
 					j++
 				}
-				if err = proxy.NewHysteria2Api(apiPort).KickUsers(kickUsernames, jwtSecret); err != nil {
+				if err = kickHysteria2Users(kickUsernames, jwtSecret); err != nil {
 					return
 				}
 			}(usernameList)
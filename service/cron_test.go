@@ -0,0 +1,91 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"h-ui/dao"
+	"h-ui/model/bo"
+	"h-ui/model/constant"
+)
+
+func TestListHysteria2UserTraffic(t *testing.T) {
+	apis := []hysteria2InstanceApi{
+		{name: "a", api: &fakeHysteria2Api{users: map[string]bo.Hysteria2UserTraffic{"user1": {Tx: 1, Rx: 2}, "user2": {Tx: 3, Rx: 4}}}},
+		{name: "b", api: &fakeHysteria2Api{err: errors.New("http connection error")}},
+		{name: "c", api: &fakeHysteria2Api{users: map[string]bo.Hysteria2UserTraffic{"user1": {Tx: 10, Rx: 20}}}},
+	}
+	users, failed := listHysteria2UserTraffic(apis, "secret")
+	want := map[string]bo.Hysteria2UserTraffic{"user1": {Tx: 11, Rx: 22}, "user2": {Tx: 3, Rx: 4}}
+	if !reflect.DeepEqual(users, want) {
+		t.Fatalf("got %v, want %v", users, want)
+	}
+	if failed != 1 {
+		t.Fatalf("failed %d, want 1", failed)
+	}
+}
+
+func TestListKickAccount(t *testing.T) {
+	initTestDb(t)
+	now := time.Now().UnixMilli()
+	saveTestAccount(t, "active", nil)
+	saveTestAccount(t, "expired", map[string]interface{}{"expire_time": now - 1})
+	saveTestAccount(t, "kicked", map[string]interface{}{"kick_util_time": now + 60000})
+	saveTestAccount(t, "overQuota", map[string]interface{}{"quota": 100, "download": 80, "upload": 30})
+	// only the download is billed, 80 of 100
+	saveTestAccount(t, "downloadBilled", map[string]interface{}{"quota": 100, "download": 80, "upload": 30, "billing_mode": "download"})
+	// not online, the expiry and the kick must not match it outside of the usernames
+	saveTestAccount(t, "offlineExpired", map[string]interface{}{"expire_time": now - 1, "kick_util_time": now + 60000})
+
+	accounts, err := listKickAccount([]string{"active", "expired", "kicked", "overQuota", "downloadBilled"}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, item := range accounts {
+		got = append(got, *item.Username)
+	}
+	sort.Strings(got)
+	want := []string{"expired", "kicked", "overQuota"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestCronResetTrafficCycleAfterAssignPlan(t *testing.T) {
+	initTestDb(t)
+	now := time.Now()
+	// put on a plan an hour ago, the last reset was on the previous plan
+	assigned := saveTestAccount(t, "assigned", map[string]interface{}{"reset_cycle": constant.ResetCycleDays, "reset_interval": 1,
+		"reset_anchor": now.Add(-time.Hour).UnixMilli(), "reset_at": now.AddDate(0, 0, -3).UnixMilli(), "download": 10})
+	due := saveTestAccount(t, "due", map[string]interface{}{"reset_cycle": constant.ResetCycleDays, "reset_interval": 1,
+		"reset_anchor": now.AddDate(0, 0, -3).UnixMilli(), "reset_at": now.AddDate(0, 0, -2).UnixMilli(), "download": 10})
+	CronResetTrafficCycle()
+
+	for id, want := range map[int64]int64{assigned: 10, due: 0} {
+		account, err := dao.GetAccount("id = ?", id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *account.Download != want {
+			t.Fatalf("%s download %d, want %d", *account.Username, *account.Download, want)
+		}
+	}
+}
+
+func TestRecoverCron(t *testing.T) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer recoverCron("test")
+		panic("boom")
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the panicking goroutine did not finish")
+	}
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"h-ui/dao"
+	"h-ui/model/entity"
+)
+
+// initTestDb a fresh database in a temporary HUI_DATA for the test
+func initTestDb(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HUI_DATA", dir+string(filepath.Separator))
+	if err := os.Mkdir(filepath.Join(dir, "data"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := dao.InitSql(""); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = dao.CloseSqliteDB()
+	})
+}
+
+// saveTestAccount an account that is neither limited nor expired, with the updates applied
+func saveTestAccount(t *testing.T, username string, updates map[string]interface{}) int64 {
+	pass := username + "pass"
+	conPass := username + "." + pass
+	var quota int64 = -1
+	var expireTime int64 = 253370736000000
+	id, err := dao.SaveAccount(entity.Account{Username: &username, Pass: &pass, ConPass: &conPass, Quota: &quota, ExpireTime: &expireTime})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) > 0 {
+		if err = dao.UpdateAccount([]int64{id}, updates); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return id
+}
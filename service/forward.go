@@ -3,8 +3,6 @@ package service
 import (
 	"errors"
 	"fmt"
-	"h-ui/dao"
-	"h-ui/model/constant"
 	"h-ui/util"
 	"strings"
 
@@ -20,6 +18,9 @@ var (
 	Comment          = "hui_hysteria_porthopping"
 )
 
+// ingressInterfaces every interface matching the detection, the first one is the ingress interface
+var ingressInterfaces []string
+
 func InitForward() {
 	if nft, err := util.Exec("command -v nft"); err == nil && strings.TrimSpace(nft) != "" {
 		netManager = "nft"
@@ -28,8 +29,11 @@ func InitForward() {
 	}
 
 	if ii, err := util.Exec("ls /sys/class/net | grep -E '^en|^eth'"); err == nil && strings.TrimSpace(ii) != "" {
-		iiList := strings.Split(ii, "\n")
+		iiList := strings.Split(strings.TrimSpace(ii), "\n")
 		ingressInterface = strings.TrimSpace(iiList[0])
+		for _, item := range iiList {
+			ingressInterfaces = append(ingressInterfaces, strings.TrimSpace(item))
+		}
 	}
 }
 
@@ -47,33 +51,31 @@ func InitTableAndChain() error {
 	return nil
 }
 
+// InitPortHopping forward the port hopping range of every instance to its listen port
 func InitPortHopping() error {
 	if err := RemoveByComment(); err != nil {
 		return err
 	}
 
-	hysteria2Config, err := GetHysteria2Config()
+	instances, err := listHysteria2Instance()
 	if err != nil {
 		return err
 	}
 	// set port forward
-	hysteria2ConfigPortHopping, err := dao.GetConfig("key = ?", constant.Hysteria2ConfigPortHopping)
-	if err != nil {
-		return err
-	}
-
-	if hysteria2ConfigPortHopping.Value != nil && *hysteria2ConfigPortHopping.Value != "" {
-		if hysteria2Config.Listen != nil {
-			listen := strings.Split(*hysteria2Config.Listen, ":")
-			if len(listen) == 2 {
-				if err := portForward(*hysteria2ConfigPortHopping.Value, listen[1], Add); err != nil {
-					return err
-				}
-			}
-		} else {
-			logrus.Error("Hysteria2 Listen configuration is nil")
+	for _, instance := range instances {
+		if instance.portHopping == "" {
+			continue
+		}
+		if instance.config.Listen == nil {
+			logrus.Errorf("%s Listen configuration is nil", instance.displayName())
 			return errors.New("hysteria2 listen configuration is nil")
 		}
+		listen := strings.Split(*instance.config.Listen, ":")
+		if len(listen) == 2 {
+			if err := portForward(instance.portHopping, listen[1], Add); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
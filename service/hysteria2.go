@@ -11,29 +11,46 @@ import (
 	"h-ui/proxy"
 	"h-ui/util"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 func InitHysteria2() error {
-	if !util.Exists(util.GetHysteria2BinPath()) {
-		if err := util.DownloadHysteria2(""); err != nil {
-			logrus.Errorf("download hysteria2 bin err: %v", err)
-			return errors.New("download hysteria2 bin err")
-		}
-	}
-
 	config, err := dao.GetConfig("key = ?", constant.Hysteria2Enable)
 	if err != nil {
 		return err
 	}
 
+	if !util.Exists(util.GetHysteria2BinPath()) {
+		// the panel keeps running without the binary so one can be uploaded
+		if err = initHysteria2Bin(); err != nil {
+			logrus.Errorf("init hysteria2 bin err: %v, upload a hysteria2 binary to start it", err)
+			return nil
+		}
+	}
+
 	if *config.Value == "1" {
 		if err = StartHysteria2(); err != nil {
 			return err
 		}
 	}
+	InitInbound()
 	return nil
 }
 
+// initHysteria2Bin use the newest installed version, only download when none is installed
+func initHysteria2Bin() error {
+	versions, err := util.ListHysteria2Version()
+	if err != nil {
+		return err
+	}
+	if len(versions) > 0 {
+		return util.UseHysteria2Version(versions[0])
+	}
+	return util.DownloadHysteria2("")
+}
+
 func setHysteria2ConfigYAML() error {
 	serverConfig, err := GetHysteria2Config()
 	if err != nil {
@@ -49,18 +66,19 @@ func setHysteria2ConfigYAML() error {
 	}
 
 	// update auth http url
-	if *serverConfig.Auth.HTTP.URL != authHttpUrl {
-		serverConfig.Auth.HTTP.URL = &authHttpUrl
-		if err := UpdateHysteria2Config(serverConfig); err != nil {
-			return err
-		}
-	}
+	urlChanged := *serverConfig.Auth.HTTP.URL != authHttpUrl
+	serverConfig.Auth.HTTP.URL = &authHttpUrl
 
 	hysteria2Config, err := yaml.Marshal(&serverConfig)
 	if err != nil {
 		logrus.Errorf("marshal hysteria2 config err: %v", err)
 		return errors.New("marshal hysteria2 config err")
 	}
+	if urlChanged {
+		if err = saveHysteria2Config(string(hysteria2Config), "system", "auth url changed"); err != nil {
+			return err
+		}
+	}
 	file, err := os.OpenFile(constant.Hysteria2ConfigPath, os.O_WRONLY|os.O_TRUNC|os.O_CREATE, 0644)
 	if err != nil {
 		logrus.Errorf("create hysteria2 server config file err: %v", err)
@@ -78,18 +96,76 @@ func Hysteria2IsRunning() bool {
 	return proxy.NewHysteria2Instance().IsRunning()
 }
 
+// StartHysteria2 hysteria2 is only started once it is alive and its traffic stats api responds within the start timeout,
+// otherwise it is stopped so the supervisor does not keep restarting a broken config
 func StartHysteria2() error {
 	if err := setHysteria2ConfigYAML(); err != nil {
 		return err
 	}
-	if err := proxy.NewHysteria2Instance().StartHysteria2(); err != nil {
+	primary, err := primaryHysteria2Instance()
+	if err != nil {
+		return err
+	}
+	if err = primary.process.StartHysteria2(); err != nil {
+		return err
+	}
+	if err = waitHysteria2Ready(primary, hysteria2Timeout(constant.Hysteria2StartTimeout)); err != nil {
+		_ = primary.process.StopHysteria2(0)
 		return err
 	}
 	return nil
 }
 
+// waitHysteria2Ready the error carries the startup log lines of hysteria2
+func waitHysteria2Ready(instance hysteria2Instance, timeout time.Duration) error {
+	hysteria2Api, err := instance.api()
+	if err != nil {
+		return err
+	}
+	jwtSecretConfig, err := dao.GetConfig("key = ?", constant.JwtSecret)
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		if !instance.process.IsRunning() {
+			return hysteria2StartError(instance.process, fmt.Sprintf("%s exited during startup", instance.displayName()))
+		}
+		if _, err = hysteria2Api.OnlineUsers(*jwtSecretConfig.Value); err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return hysteria2StartError(instance.process, fmt.Sprintf("%s did not respond within %s", instance.displayName(), timeout))
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+func hysteria2StartError(process *proxy.Hysteria2Process, reason string) error {
+	logs := process.StartupLogs()
+	logrus.Errorf("%s, startup logs: %s", reason, strings.Join(logs, "\n"))
+	if len(logs) == 0 {
+		return errors.New(reason)
+	}
+	return fmt.Errorf("%s:\n%s", reason, strings.Join(logs, "\n"))
+}
+
 func StopHysteria2() error {
-	return proxy.NewHysteria2Instance().StopHysteria2()
+	return proxy.NewHysteria2Instance().StopHysteria2(hysteria2Timeout(constant.Hysteria2StopTimeout))
+}
+
+// hysteria2Timeout the start or stop timeout of hysteria2
+func hysteria2Timeout(key string) time.Duration {
+	timeout, err := dao.GetConfig("key = ?", key)
+	if err != nil {
+		return 10 * time.Second
+	}
+	seconds, err := strconv.ParseInt(*timeout.Value, 10, 64)
+	if err != nil || seconds < 0 {
+		logrus.Errorf("%s string conv int64 err: %v", key, err)
+		return 10 * time.Second
+	}
+	return time.Duration(seconds) * time.Second
 }
 
 func RestartHysteria2() error {
@@ -102,8 +178,25 @@ func RestartHysteria2() error {
 	return nil
 }
 
+// ReloadHysteria2 save the traffic since the last sync then restart, the stop closes the sessions with SIGTERM
+func ReloadHysteria2() error {
+	if Hysteria2IsRunning() {
+		jwtSecretConfig, err := dao.GetConfig("key = ?", constant.JwtSecret)
+		if err != nil {
+			return err
+		}
+		if err = flushAccountTraffic(*jwtSecretConfig.Value); err != nil {
+			return err
+		}
+	}
+	return RestartHysteria2()
+}
+
 func ReleaseHysteria2() error {
-	return proxy.NewHysteria2Instance().Release()
+	if err := proxy.NewHysteria2Instance().Release(); err != nil {
+		return err
+	}
+	return ReleaseInbounds()
 }
 
 func Hysteria2AcmePath() (vo.Hysteria2AcmePathVo, error) {
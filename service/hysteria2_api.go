@@ -3,28 +3,30 @@ package service
 import (
 	"errors"
 	"fmt"
+	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
 	"h-ui/dao"
 	"h-ui/model/bo"
 	"h-ui/model/constant"
-	"h-ui/proxy"
+	"h-ui/model/entity"
+	"h-ui/util"
 	"net/url"
 	"strings"
 	"time"
 )
 
 func Hysteria2Auth(conPass string) (int64, string, error) {
-	if !Hysteria2IsRunning() {
+	if !Hysteria2AnyRunning() {
 		return 0, "", errors.New("hysteria2 is not running")
 	}
 
 	now := time.Now().UnixMilli()
-	account, err := dao.GetAccount("con_pass = ? and deleted = 0 and (quota < 0 or quota > download + upload) and ? < expire_time and ? > kick_util_time", conPass, now, now)
+	account, err := dao.GetAccount(fmt.Sprintf("con_pass = ? and deleted = 0 and trashed_at = 0 and (quota < 0 or quota > %s) and ? < expire_time and ? > kick_util_time", dao.AccountBilledTraffic), conPass, now, now)
 	if err != nil {
 		return 0, "", err
 	}
 
-	// 限制设备数
+	// 限制设备数，设备数是所有实例的合计
 	onlineUsers, err := Hysteria2Online()
 	if err != nil {
 		return 0, "", err
@@ -37,27 +39,44 @@ func Hysteria2Auth(conPass string) (int64, string, error) {
 	return *account.Id, *account.Username, nil
 }
 
+// Hysteria2Online the devices of every online user summed over the running instances
 func Hysteria2Online() (map[string]int64, error) {
-	if !Hysteria2IsRunning() {
+	if !Hysteria2AnyRunning() {
 		return map[string]int64{}, nil
 	}
-	apiPort, err := GetHysteria2ApiPort()
-	if err != nil {
-		return nil, errors.New("get hysteria2 apiPort err")
-	}
 	jwtSecretConfig, err := dao.GetConfig("key = ?", constant.JwtSecret)
 	if err != nil {
 		return nil, err
 	}
-	onlineUsers, err := proxy.NewHysteria2Api(apiPort).OnlineUsers(*jwtSecretConfig.Value)
+	return hysteria2Online(*jwtSecretConfig.Value)
+}
+
+func hysteria2Online(jwtSecret string) (map[string]int64, error) {
+	apis, _, err := listRunningHysteria2Api()
 	if err != nil {
 		return nil, err
 	}
-	return onlineUsers, nil
+	return onlineHysteria2Users(apis, jwtSecret), nil
+}
+
+// onlineHysteria2Users an instance whose api fails is logged and skipped, the devices on the others are still counted
+func onlineHysteria2Users(apis []hysteria2InstanceApi, jwtSecret string) map[string]int64 {
+	onlineUsers := map[string]int64{}
+	for _, item := range apis {
+		instanceOnlineUsers, err := item.api.OnlineUsers(jwtSecret)
+		if err != nil {
+			logrus.Errorf("%s online users err: %v", item.name, err)
+			continue
+		}
+		for username, device := range instanceOnlineUsers {
+			onlineUsers[username] += device
+		}
+	}
+	return onlineUsers
 }
 
 func Hysteria2Kick(ids []int64, kickUtilTime int64) error {
-	if !Hysteria2IsRunning() {
+	if !Hysteria2AnyRunning() {
 		return errors.New("hysteria2 is not running")
 	}
 	if err := dao.UpdateAccount(ids, map[string]interface{}{"kick_util_time": kickUtilTime}); err != nil {
@@ -72,18 +91,39 @@ func Hysteria2Kick(ids []int64, kickUtilTime int64) error {
 	for _, item := range accounts {
 		keys = append(keys, *item.Username)
 	}
-	apiPort, err := GetHysteria2ApiPort()
-	if err != nil {
-		return errors.New("get hysteria2 apiPort err")
-	}
+	return kickUsers(keys)
+}
+
+func kickUsers(keys []string) error {
 	jwtSecretConfig, err := dao.GetConfig("key = ?", constant.JwtSecret)
 	if err != nil {
 		return err
 	}
-	if err = proxy.NewHysteria2Api(apiPort).KickUsers(keys, *jwtSecretConfig.Value); err != nil {
+	return kickHysteria2Users(keys, *jwtSecretConfig.Value)
+}
+
+// kickHysteria2Users kick the users from every running instance, they may be connected to more than one
+func kickHysteria2Users(keys []string, jwtSecret string) error {
+	apis, skipped, err := listRunningHysteria2Api()
+	if err != nil {
 		return err
 	}
-	return nil
+	err = kickHysteria2ApiUsers(apis, keys, jwtSecret)
+	if skipped > 0 {
+		err = errors.Join(err, fmt.Errorf("%d instances without a usable api", skipped))
+	}
+	return err
+}
+
+// kickHysteria2ApiUsers the users are kicked on every instance even when one of them fails, the errors are joined
+func kickHysteria2ApiUsers(apis []hysteria2InstanceApi, keys []string, jwtSecret string) error {
+	var errs []error
+	for _, item := range apis {
+		if err := item.api.KickUsers(keys, jwtSecret); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", item.name, err))
+		}
+	}
+	return errors.Join(errs...)
 }
 
 func Hysteria2SubscribeUrl(accountId int64, protocol string, host string) (string, error) {
@@ -91,33 +131,23 @@ func Hysteria2SubscribeUrl(accountId int64, protocol string, host string) (strin
 	if err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("%s//%s/hui/%s", protocol, host, *account.ConPass), nil
+	return hysteria2SubscribeUrl(*account.ConPass, protocol, host), nil
+}
+
+func hysteria2SubscribeUrl(conPass string, protocol string, host string) string {
+	return fmt.Sprintf("%s//%s/hui/%s", protocol, host, conPass)
 }
 
 func Hysteria2Subscribe(conPass string, clientType string, host string) (string, string, error) {
-	hysteria2Config, err := GetHysteria2Config()
+	instances, err := listHysteria2Instance()
 	if err != nil {
 		return "", "", err
 	}
-	if hysteria2Config.Listen == nil || *hysteria2Config.Listen == "" {
+	if len(instances) == 0 {
 		return "", "", errors.New("hysteria2 config is empty")
 	}
 
-	account, err := dao.GetAccount("con_pass = ?", conPass)
-	if err != nil {
-		return "", "", err
-	}
-
-	hysteria2Name := "hysteria2"
-	hysteria2ConfigRemark, err := dao.GetConfig("key = ?", constant.Hysteria2ConfigRemark)
-	if err != nil {
-		return "", "", err
-	}
-	if *hysteria2ConfigRemark.Value != "" {
-		hysteria2Name = *hysteria2ConfigRemark.Value
-	}
-
-	hysteria2ConfigPortHopping, err := dao.GetConfig("key = ?", constant.Hysteria2ConfigPortHopping)
+	account, err := dao.GetAccount("con_pass = ? and trashed_at = 0", conPass)
 	if err != nil {
 		return "", "", err
 	}
@@ -125,60 +155,40 @@ func Hysteria2Subscribe(conPass string, clientType string, host string) (string,
 	userInfo := ""
 	configStr := ""
 	if clientType == constant.Shadowrocket || clientType == constant.Clash {
+		// the clients compare upload + download with the total, so only the billed directions are reported, the
+		// traffic multiplier is already applied to the saved traffic
+		download, upload := util.BilledTrafficSplit(*account.BillingMode, *account.Download, *account.Upload)
 		userInfo = fmt.Sprintf("upload=%d; download=%d; total=%d; expire=%d",
-			*account.Upload,
-			*account.Download,
+			upload,
+			download,
 			*account.Quota,
 			*account.ExpireTime/1000)
 
-		hysteria2 := bo.Hysteria2{
-			Name:     hysteria2Name,
-			Type:     "hysteria2",
-			Server:   strings.Split(host, ":")[0],
-			Port:     strings.Split(*hysteria2Config.Listen, ":")[1],
-			Ports:    *hysteria2ConfigPortHopping.Value,
-			Password: conPass,
-		}
-
-		if hysteria2Config.Bandwidth != nil {
-			if hysteria2Config.Bandwidth.Up != nil &&
-				*hysteria2Config.Bandwidth.Up != "" {
-				hysteria2.Up = *hysteria2Config.Bandwidth.Up
+		var proxies []interface{}
+		var proxyNames []string
+		names := map[string]bool{}
+		for _, instance := range instances {
+			hysteria2 := hysteria2Proxy(instance, conPass, strings.Split(host, ":")[0])
+			// the proxy names must be unique within a clash config
+			if names[hysteria2.Name] {
+				hysteria2.Name = fmt.Sprintf("%s-%d", hysteria2.Name, instance.id)
 			}
-			if hysteria2Config.Bandwidth.Down != nil &&
-				*hysteria2Config.Bandwidth.Down != "" {
-				hysteria2.Down = *hysteria2Config.Bandwidth.Down
-			}
-		}
-
-		if hysteria2Config.Obfs != nil &&
-			hysteria2Config.Obfs.Type != nil &&
-			*hysteria2Config.Obfs.Type == "salamander" &&
-			hysteria2Config.Obfs.Salamander != nil &&
-			hysteria2Config.Obfs.Salamander.Password != nil &&
-			*hysteria2Config.Obfs.Salamander.Password != "" {
-			hysteria2.Obfs = *hysteria2Config.Obfs.Salamander.Password
-		}
-
-		if hysteria2Config.ACME != nil &&
-			hysteria2Config.ACME.Domains != nil &&
-			len(hysteria2Config.ACME.Domains) > 0 {
-			hysteria2.Sni = hysteria2Config.ACME.Domains[0]
+			names[hysteria2.Name] = true
+			proxies = append(proxies, hysteria2)
+			proxyNames = append(proxyNames, hysteria2.Name)
 		}
 
-		hysteria2.SkipCertVerify = false
-
 		proxyGroup := bo.ProxyGroup{
 			Name:    "PROXY",
 			Type:    "select",
-			Proxies: []string{hysteria2Name},
+			Proxies: proxyNames,
 		}
 
 		clashConfig := bo.ClashConfig{
 			ProxyGroups: []bo.ProxyGroup{
 				proxyGroup,
 			},
-			Proxies: []interface{}{hysteria2},
+			Proxies: proxies,
 		}
 		clashConfigYaml, err := yaml.Marshal(&clashConfig)
 		if err != nil {
@@ -195,30 +205,86 @@ func Hysteria2Subscribe(conPass string, clientType string, host string) (string,
 			}
 		}
 	} else if clientType == constant.V2rayN {
-		hysteria2Url, err := Hysteria2Url(*account.Id, strings.Split(host, ":")[0])
-		if err != nil {
-			return "", "", err
+		// one link per line
+		var urls []string
+		for _, instance := range instances {
+			urls = append(urls, hysteria2Url(instance, conPass, strings.Split(host, ":")[0]))
 		}
-		configStr = hysteria2Url
+		configStr = strings.Join(urls, "\n")
 	}
 
 	return userInfo, configStr, nil
 }
 
-func Hysteria2Url(accountId int64, hostname string) (string, error) {
-	hysteria2Config, err := GetHysteria2Config()
-	if err != nil {
-		return "", err
+func hysteria2Proxy(instance hysteria2Instance, conPass string, server string) bo.Hysteria2 {
+	hysteria2Config := instance.config
+	hysteria2 := bo.Hysteria2{
+		Name:     instance.displayName(),
+		Type:     "hysteria2",
+		Server:   server,
+		Port:     strings.Split(*hysteria2Config.Listen, ":")[1],
+		Ports:    instance.portHopping,
+		Password: conPass,
 	}
-	if hysteria2Config.Listen == nil || *hysteria2Config.Listen == "" {
-		return "", errors.New("hysteria2 config is empty")
+
+	if hysteria2Config.Bandwidth != nil {
+		if hysteria2Config.Bandwidth.Up != nil &&
+			*hysteria2Config.Bandwidth.Up != "" {
+			hysteria2.Up = *hysteria2Config.Bandwidth.Up
+		}
+		if hysteria2Config.Bandwidth.Down != nil &&
+			*hysteria2Config.Bandwidth.Down != "" {
+			hysteria2.Down = *hysteria2Config.Bandwidth.Down
+		}
+	}
+
+	if hysteria2Config.Obfs != nil &&
+		hysteria2Config.Obfs.Type != nil &&
+		*hysteria2Config.Obfs.Type == "salamander" &&
+		hysteria2Config.Obfs.Salamander != nil &&
+		hysteria2Config.Obfs.Salamander.Password != nil &&
+		*hysteria2Config.Obfs.Salamander.Password != "" {
+		hysteria2.Obfs = *hysteria2Config.Obfs.Salamander.Password
 	}
 
+	if hysteria2Config.ACME != nil &&
+		hysteria2Config.ACME.Domains != nil &&
+		len(hysteria2Config.ACME.Domains) > 0 {
+		hysteria2.Sni = hysteria2Config.ACME.Domains[0]
+	}
+
+	hysteria2.SkipCertVerify = false
+	return hysteria2
+}
+
+// Hysteria2Url the link of the account to the inbound, the inbound id 0 is the hysteria2 configured by HYSTERIA2_CONFIG
+func Hysteria2Url(accountId int64, hostname string, inboundId int64) (string, error) {
 	account, err := dao.GetAccount("id = ?", accountId)
 	if err != nil {
 		return "", err
 	}
+	var instance hysteria2Instance
+	if inboundId == 0 {
+		instance, err = primaryHysteria2Instance()
+	} else {
+		var inbound entity.Inbound
+		inbound, err = dao.GetInbound("id = ?", inboundId)
+		if err != nil {
+			return "", err
+		}
+		instance, err = inboundHysteria2Instance(inbound)
+	}
+	if err != nil {
+		return "", err
+	}
+	if instance.config.Listen == nil || *instance.config.Listen == "" {
+		return "", errors.New("hysteria2 config is empty")
+	}
+	return hysteria2Url(instance, *account.ConPass, hostname), nil
+}
 
+func hysteria2Url(instance hysteria2Instance, conPass string, hostname string) string {
+	hysteria2Config := instance.config
 	urlConfig := ""
 	if hysteria2Config.Obfs != nil &&
 		hysteria2Config.Obfs.Type != nil &&
@@ -246,24 +312,16 @@ func Hysteria2Url(accountId int64, hostname string) (string, error) {
 		urlConfig += fmt.Sprintf("&downmbps=%s", url.PathEscape(*hysteria2Config.Bandwidth.Down))
 	}
 
-	hysteria2ConfigPortHopping, err := dao.GetConfig("key = ?", constant.Hysteria2ConfigPortHopping)
-	if err != nil {
-		return "", err
-	}
-	if *hysteria2ConfigPortHopping.Value != "" {
+	if instance.portHopping != "" {
 		// shadowrocket
-		urlConfig += fmt.Sprintf("&mport=%s", *hysteria2ConfigPortHopping.Value)
+		urlConfig += fmt.Sprintf("&mport=%s", instance.portHopping)
 	}
 
-	hysteria2ConfigRemark, err := dao.GetConfig("key = ?", constant.Hysteria2ConfigRemark)
-	if err != nil {
-		return "", err
-	}
-	if *hysteria2ConfigRemark.Value != "" {
-		urlConfig += fmt.Sprintf("#%s", *hysteria2ConfigRemark.Value)
+	if instance.name != "" {
+		urlConfig += fmt.Sprintf("#%s", instance.name)
 	}
 	if urlConfig != "" {
 		urlConfig = "/?" + strings.TrimPrefix(urlConfig, "&")
 	}
-	return fmt.Sprintf("hysteria2://%s@%s%s", *account.ConPass, hostname, *hysteria2Config.Listen) + urlConfig, nil
+	return fmt.Sprintf("hysteria2://%s@%s%s", conPass, hostname, *hysteria2Config.Listen) + urlConfig
 }
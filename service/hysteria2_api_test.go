@@ -0,0 +1,77 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"h-ui/model/bo"
+)
+
+// fakeHysteria2Api a traffic stats api whose calls fail when err is set
+type fakeHysteria2Api struct {
+	users  map[string]bo.Hysteria2UserTraffic
+	online map[string]int64
+	err    error
+	kicked []string
+}
+
+func (f *fakeHysteria2Api) ListUsers(clear bool, secret string) (map[string]bo.Hysteria2UserTraffic, error) {
+	return f.users, f.err
+}
+
+func (f *fakeHysteria2Api) KickUsers(keys []string, secret string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.kicked = append(f.kicked, keys...)
+	return nil
+}
+
+func (f *fakeHysteria2Api) OnlineUsers(secret string) (map[string]int64, error) {
+	return f.online, f.err
+}
+
+func TestOnlineHysteria2Users(t *testing.T) {
+	apis := []hysteria2InstanceApi{
+		{name: "a", api: &fakeHysteria2Api{online: map[string]int64{"user1": 1, "user2": 2}}},
+		{name: "b", api: &fakeHysteria2Api{err: errors.New("http connection error")}},
+		{name: "c", api: &fakeHysteria2Api{online: map[string]int64{"user1": 2}}},
+	}
+	got := onlineHysteria2Users(apis, "secret")
+	want := map[string]int64{"user1": 3, "user2": 2}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestKickHysteria2ApiUsers(t *testing.T) {
+	a := &fakeHysteria2Api{}
+	b := &fakeHysteria2Api{err: errors.New("http connection error")}
+	c := &fakeHysteria2Api{}
+	apis := []hysteria2InstanceApi{{name: "a", api: a}, {name: "b", api: b}, {name: "c", api: c}}
+
+	err := kickHysteria2ApiUsers(apis, []string{"user1"}, "secret")
+	if err == nil || !strings.Contains(err.Error(), "b: http connection error") {
+		t.Fatalf("got %v, want the error of b", err)
+	}
+	// the instances after the failing one are kicked as well
+	for name, api := range map[string]*fakeHysteria2Api{"a": a, "c": c} {
+		if !reflect.DeepEqual(api.kicked, []string{"user1"}) {
+			t.Fatalf("%s kicked %v, want [user1]", name, api.kicked)
+		}
+	}
+	if err = kickHysteria2ApiUsers(apis[:1], []string{"user2"}, "secret"); err != nil {
+		t.Fatalf("got %v, want nil", err)
+	}
+}
+
+func TestHysteria2UrlInvalidInbound(t *testing.T) {
+	initTestDb(t)
+	accountId := saveTestAccount(t, "user01", nil)
+	inboundId := saveTestInbound(t, "broken", "listen: [", "")
+	if _, err := Hysteria2Url(accountId, "example.com", inboundId); err == nil || err.Error() != "inbound broken config is invalid" {
+		t.Errorf("got %v, want inbound broken config is invalid", err)
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"fmt"
+	"h-ui/dao"
+	"h-ui/model/constant"
+	"h-ui/model/dto"
+	"h-ui/model/entity"
+	"h-ui/util"
+)
+
+// saveHysteria2Config save the yaml and record it as a revision with the diff against the previous one, an unchanged config is not recorded
+func saveHysteria2Config(yamlConfig string, operator string, remark string) error {
+	config, err := dao.GetConfig("key = ?", constant.Hysteria2Config)
+	if err != nil {
+		return err
+	}
+	if *config.Value == yamlConfig {
+		return nil
+	}
+	diff := util.UnifiedDiff("previous", "current", *config.Value, yamlConfig)
+	return dao.UpdateHysteria2ConfigRevision(entity.Hysteria2ConfigRevision{
+		Config:   &yamlConfig,
+		Diff:     &diff,
+		Operator: &operator,
+		Remark:   &remark,
+	})
+}
+
+// applyHysteria2Config save the config and reload hysteria2 if it is running, when it does not come up
+// the previous config is restored and hysteria2 restarted with it
+func applyHysteria2Config(yamlConfig string, operator string, remark string) error {
+	previous, err := dao.GetConfig("key = ?", constant.Hysteria2Config)
+	if err != nil {
+		return err
+	}
+	if err = saveHysteria2Config(yamlConfig, operator, remark); err != nil {
+		return err
+	}
+	if !Hysteria2IsRunning() {
+		return nil
+	}
+	startErr := ReloadHysteria2()
+	if startErr == nil {
+		return nil
+	}
+
+	if err = saveHysteria2Config(*previous.Value, operator, "automatic rollback, hysteria2 failed to start"); err != nil {
+		return fmt.Errorf("%v\nthe previous config could not be restored: %v", startErr, err)
+	}
+	if err = RestartHysteria2(); err != nil {
+		return fmt.Errorf("%v\nthe previous config is restored but hysteria2 failed to start with it: %v", startErr, err)
+	}
+	return fmt.Errorf("%v\nthe previous config is restored", startErr)
+}
+
+func PageHysteria2ConfigRevision(baseDto dto.BaseDto) ([]entity.Hysteria2ConfigRevision, int64, error) {
+	return dao.PageHysteria2ConfigRevision(baseDto)
+}
+
+func GetHysteria2ConfigRevision(id int64) (entity.Hysteria2ConfigRevision, error) {
+	return dao.GetHysteria2ConfigRevision("id = ?", id)
+}
+
+// DiffHysteria2ConfigRevision the diff from one revision to another, either may be older
+func DiffHysteria2ConfigRevision(fromId int64, toId int64) (string, error) {
+	from, err := dao.GetHysteria2ConfigRevision("id = ?", fromId)
+	if err != nil {
+		return "", err
+	}
+	to, err := dao.GetHysteria2ConfigRevision("id = ?", toId)
+	if err != nil {
+		return "", err
+	}
+	return util.UnifiedDiff(fmt.Sprintf("revision %d", fromId), fmt.Sprintf("revision %d", toId), *from.Config, *to.Config), nil
+}
+
+// RollbackHysteria2Config apply the config of the revision as a new revision
+func RollbackHysteria2Config(id int64, operator string) error {
+	revision, err := dao.GetHysteria2ConfigRevision("id = ?", id)
+	if err != nil {
+		return err
+	}
+	return applyHysteria2Config(*revision.Config, operator, fmt.Sprintf("rollback to revision %d", id))
+}
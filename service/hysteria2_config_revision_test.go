@@ -0,0 +1,43 @@
+package service
+
+import (
+	"testing"
+
+	"h-ui/dao"
+	"h-ui/model/constant"
+	"h-ui/model/dto"
+)
+
+func TestSaveHysteria2ConfigBaseline(t *testing.T) {
+	initTestDb(t)
+	// the config saved before the revisions were recorded
+	if err := dao.UpdateConfig([]string{constant.Hysteria2Config}, map[string]interface{}{"value": "listen: :443\n"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := saveHysteria2Config("listen: :8443\n", "admin", "change the port"); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveHysteria2Config("listen: :9443\n", "admin", "change the port again"); err != nil {
+		t.Fatal(err)
+	}
+	baseline, err := dao.GetHysteria2ConfigRevision("config = ?", "listen: :443\n")
+	if err != nil {
+		t.Fatalf("the config before the first change is not recorded: %v", err)
+	}
+	var pageNum, pageSize int64 = 1, 10
+	if _, total, err := dao.PageHysteria2ConfigRevision(dto.BaseDto{PageNum: &pageNum, PageSize: &pageSize}); err != nil || total != 3 {
+		t.Errorf("got %d revisions, want the baseline and the two changes", total)
+	}
+
+	if err = RollbackHysteria2Config(*baseline.Id, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	config, err := dao.GetConfig("key = ?", constant.Hysteria2Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *config.Value != "listen: :443\n" {
+		t.Errorf("got the config %q after the rollback, want the baseline", *config.Value)
+	}
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"h-ui/model/bo"
+	"h-ui/proxy"
+	"h-ui/util"
+	"sync"
+
+	"github.com/shirou/gopsutil/process"
+	"github.com/sirupsen/logrus"
+)
+
+// hysteria2MetricsMax an hour of samples at one sample every 10 seconds
+const hysteria2MetricsMax = 360
+
+type hysteria2MetricsHistory struct {
+	process *process.Process // kept between samples, the cpu percent is measured against the last sample
+	history []bo.ProcessMetrics
+}
+
+// hysteria2Metrics the history of every instance by id, 0 is the hysteria2 configured by HYSTERIA2_CONFIG
+var hysteria2Metrics = struct {
+	mutex     sync.Mutex
+	instances map[int64]*hysteria2MetricsHistory
+}{instances: map[int64]*hysteria2MetricsHistory{}}
+
+// CronSampleHysteria2Metrics sample the resource usage of every hysteria2 process, the history starts over when it is restarted
+func CronSampleHysteria2Metrics() {
+	processes := map[int64]*proxy.Hysteria2Process{0: proxy.NewHysteria2Instance()}
+	for id, item := range proxy.ListHysteria2InboundInstance() {
+		processes[id] = item
+	}
+
+	hysteria2Metrics.mutex.Lock()
+	defer hysteria2Metrics.mutex.Unlock()
+	for id := range hysteria2Metrics.instances {
+		if _, exist := processes[id]; !exist {
+			delete(hysteria2Metrics.instances, id)
+		}
+	}
+	for id, item := range processes {
+		sampleHysteria2Metrics(id, item.Pid())
+	}
+}
+
+func sampleHysteria2Metrics(id int64, pid int) {
+	metrics, exist := hysteria2Metrics.instances[id]
+	if pid == 0 {
+		delete(hysteria2Metrics.instances, id)
+		return
+	}
+	if !exist || metrics.process.Pid != int32(pid) {
+		p, err := process.NewProcess(int32(pid))
+		if err != nil {
+			logrus.Errorf("new process %d err: %v", pid, err)
+			return
+		}
+		hysteria2Metrics.instances[id] = &hysteria2MetricsHistory{process: p}
+		// the first cpu percent is only the baseline
+		if _, err = util.GetProcessMetrics(p); err != nil {
+			logrus.Errorf("%v", err)
+		}
+		return
+	}
+
+	sample, err := util.GetProcessMetrics(metrics.process)
+	if err != nil {
+		logrus.Errorf("%v", err)
+		return
+	}
+	metrics.history = append(metrics.history, sample)
+	if len(metrics.history) > hysteria2MetricsMax {
+		metrics.history = metrics.history[len(metrics.history)-hysteria2MetricsMax:]
+	}
+}
+
+// ListHysteria2Metrics the samples of the running hysteria2 of the inbound, oldest first,
+// the inbound id 0 is the hysteria2 configured by HYSTERIA2_CONFIG
+func ListHysteria2Metrics(inboundId int64) []bo.ProcessMetrics {
+	hysteria2Process, exist := proxy.ListHysteria2InboundInstance()[inboundId]
+	if inboundId == 0 {
+		hysteria2Process, exist = proxy.NewHysteria2Instance(), true
+	}
+	if !exist {
+		return []bo.ProcessMetrics{}
+	}
+	pid := hysteria2Process.Pid()
+
+	hysteria2Metrics.mutex.Lock()
+	defer hysteria2Metrics.mutex.Unlock()
+	metrics, exist := hysteria2Metrics.instances[inboundId]
+	if !exist || int(metrics.process.Pid) != pid {
+		return []bo.ProcessMetrics{}
+	}
+	return append([]bo.ProcessMetrics{}, metrics.history...)
+}
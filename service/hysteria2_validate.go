@@ -0,0 +1,444 @@
+package service
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"h-ui/dao"
+	"h-ui/model/bo"
+	"h-ui/model/constant"
+	"h-ui/model/vo"
+	"h-ui/util"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ValidateHysteria2Config the checks the struct tags cannot express, nothing is applied
+func ValidateHysteria2Config(hysteria2ServerConfig bo.Hysteria2ServerConfig) ([]vo.FieldErrorVo, error) {
+	var running *bo.Hysteria2ServerConfig
+	if Hysteria2IsRunning() {
+		if config, err := GetHysteria2Config(); err == nil {
+			running = &config
+		}
+	}
+	portHopping, err := dao.GetConfig("key = ?", constant.Hysteria2ConfigPortHopping)
+	if err != nil {
+		return nil, err
+	}
+	return validateHysteria2Config(0, hysteria2ServerConfig, *portHopping.Value, running)
+}
+
+// ValidateInboundConfig ValidateHysteria2Config for the config of an inbound, the id of a new inbound is 0
+func ValidateInboundConfig(id int64, hysteria2ServerConfig bo.Hysteria2ServerConfig, portHopping string) ([]vo.FieldErrorVo, error) {
+	var running *bo.Hysteria2ServerConfig
+	if id != 0 && InboundIsRunning(id) {
+		if inbound, err := dao.GetInbound("id = ?", id); err == nil {
+			if instance, err := inboundHysteria2Instance(inbound); err == nil {
+				running = &instance.config
+			}
+		}
+	}
+	return validateHysteria2Config(id, hysteria2ServerConfig, portHopping, running)
+}
+
+// ValidateHysteria2PortHopping the port hopping of an instance must not overlap the ports of another one, the id of
+// the instance configured by HYSTERIA2_CONFIG is 0
+func ValidateHysteria2PortHopping(id int64, portHopping string) ([]vo.FieldErrorVo, error) {
+	var config bo.Hysteria2ServerConfig
+	if id == 0 {
+		instance, err := primaryHysteria2Instance()
+		if err != nil {
+			return nil, err
+		}
+		config = instance.config
+	} else {
+		inbound, err := dao.GetInbound("id = ?", id)
+		if err != nil {
+			return nil, err
+		}
+		if config, err = GetInboundConfig(inbound); err != nil {
+			return nil, err
+		}
+	}
+	fieldErrors := []vo.FieldErrorVo{}
+	addError := func(field string, format string, a ...any) {
+		// the conflicts of the ports that are not changed are reported when the config is saved
+		if field == "portHopping" {
+			fieldErrors = append(fieldErrors, vo.FieldErrorVo{Field: field, Message: fmt.Sprintf(format, a...)})
+		}
+	}
+	if err := validateHysteria2PortConflict(id, config, portHopping, addError); err != nil {
+		return nil, err
+	}
+	return fieldErrors, nil
+}
+
+// validateHysteria2Config id is the instance the config belongs to, running the config it is running with if any
+func validateHysteria2Config(id int64, hysteria2ServerConfig bo.Hysteria2ServerConfig, portHopping string, running *bo.Hysteria2ServerConfig) ([]vo.FieldErrorVo, error) {
+	fieldErrors := []vo.FieldErrorVo{}
+	addError := func(field string, format string, a ...any) {
+		fieldErrors = append(fieldErrors, vo.FieldErrorVo{Field: field, Message: fmt.Sprintf(format, a...)})
+	}
+
+	validateHysteria2Tls(hysteria2ServerConfig, addError)
+	if err := validateHysteria2Port(hysteria2ServerConfig, running, addError); err != nil {
+		return nil, err
+	}
+	if err := validateHysteria2PortConflict(id, hysteria2ServerConfig, portHopping, addError); err != nil {
+		return nil, err
+	}
+	validateHysteria2Obfs(hysteria2ServerConfig, addError)
+	validateHysteria2Acl(hysteria2ServerConfig, addError)
+	return fieldErrors, nil
+}
+
+func validateHysteria2Tls(config bo.Hysteria2ServerConfig, addError func(string, string, ...any)) {
+	if config.TLS != nil && config.ACME != nil {
+		addError("tls", "tls and acme cannot be set at the same time")
+		return
+	}
+	if config.TLS == nil && config.ACME == nil {
+		addError("tls", "either tls or acme is required")
+		return
+	}
+	if config.TLS != nil && config.TLS.Cert != nil && config.TLS.Key != nil {
+		certExist := util.Exists(*config.TLS.Cert)
+		keyExist := util.Exists(*config.TLS.Key)
+		if !certExist {
+			addError("tls.cert", "file %s not exist", *config.TLS.Cert)
+		}
+		if !keyExist {
+			addError("tls.key", "file %s not exist", *config.TLS.Key)
+		}
+		if certExist && keyExist {
+			if _, err := tls.LoadX509KeyPair(*config.TLS.Cert, *config.TLS.Key); err != nil {
+				addError("tls.key", "the key does not match the cert: %v", err)
+			}
+		}
+	}
+	if config.ACME != nil && config.ACME.Type != nil && *config.ACME.Type != "" {
+		switch *config.ACME.Type {
+		case "http":
+			if config.ACME.HTTP == nil {
+				addError("acme.http", "required by the acme type http")
+			}
+		case "tls":
+			if config.ACME.TLS == nil {
+				addError("acme.tls", "required by the acme type tls")
+			}
+		case "dns":
+			if config.ACME.DNS == nil {
+				addError("acme.dns", "required by the acme type dns")
+			}
+		default:
+			addError("acme.type", "acme type %s is not supported", *config.ACME.Type)
+		}
+	}
+}
+
+// validateHysteria2Port the ports must be valid, differ from the panel port and be free, unless the running hysteria2 holds them
+func validateHysteria2Port(config bo.Hysteria2ServerConfig, running *bo.Hysteria2ServerConfig, addError func(string, string, ...any)) error {
+	webPort, err := dao.GetConfig("key = ?", constant.HUIWebPort)
+	if err != nil {
+		return err
+	}
+	var runningListenPort, runningApiPort int64
+	if running != nil {
+		if running.Listen != nil {
+			runningListenPort, _ = listenPort(*running.Listen)
+		}
+		if running.TrafficStats != nil && running.TrafficStats.Listen != nil {
+			runningApiPort, _ = listenPort(*running.TrafficStats.Listen)
+		}
+	}
+
+	ports := []struct {
+		field   string
+		listen  *string
+		network string
+		running int64
+	}{
+		{"listen", config.Listen, "udp", runningListenPort},
+		{"trafficStats.listen", nil, "tcp", runningApiPort},
+	}
+	if config.TrafficStats != nil {
+		ports[1].listen = config.TrafficStats.Listen
+	}
+	for _, item := range ports {
+		if item.listen == nil {
+			continue
+		}
+		port, err := listenPort(*item.listen)
+		if err != nil {
+			addError(item.field, "%s is not a valid listen address", *item.listen)
+			continue
+		}
+		if strconv.FormatInt(port, 10) == *webPort.Value {
+			addError(item.field, "port %d is used by the panel", port)
+			continue
+		}
+		if port != item.running && !util.IsPortAvailable(uint(port), item.network) {
+			addError(item.field, "%s port %d is in use", item.network, port)
+		}
+	}
+	return nil
+}
+
+// validateHysteria2PortConflict the listen port, the port hopping and the traffic stats port must not be used by
+// another instance, whether it is running or not. The port hopping of an instance redirects its udp ports, so it must
+// neither overlap the port hopping nor the listen port of another instance.
+func validateHysteria2PortConflict(id int64, config bo.Hysteria2ServerConfig, portHopping string, addError func(string, string, ...any)) error {
+	var others []hysteria2Instance
+	if id != 0 {
+		primary, err := primaryHysteria2Instance()
+		if err != nil {
+			return err
+		}
+		others = append(others, primary)
+	}
+	inbounds, err := dao.ListInbound("id != ?", id)
+	if err != nil {
+		return err
+	}
+	for _, item := range inbounds {
+		if instance, err := inboundHysteria2Instance(item); err == nil {
+			others = append(others, instance)
+		}
+	}
+
+	type udpUse struct {
+		ports portRange
+		owner string
+	}
+	var udpUsed []udpUse
+	tcpUsed := map[int64]string{}
+	for _, other := range others {
+		if other.config.Listen != nil {
+			if port, err := listenPort(*other.config.Listen); err == nil {
+				udpUsed = append(udpUsed, udpUse{ports: portRange{start: port, end: port}, owner: fmt.Sprintf("the listen port of %s", other.displayName())})
+			}
+		}
+		for _, ports := range parsePortHopping(other.portHopping) {
+			udpUsed = append(udpUsed, udpUse{ports: ports, owner: fmt.Sprintf("the port hopping of %s", other.displayName())})
+		}
+		if other.config.TrafficStats != nil && other.config.TrafficStats.Listen != nil {
+			if port, err := listenPort(*other.config.TrafficStats.Listen); err == nil {
+				tcpUsed[port] = other.displayName()
+			}
+		}
+	}
+	checkUdp := func(field string, ports portRange) {
+		for _, used := range udpUsed {
+			if ports.overlaps(used.ports) {
+				addError(field, "udp port %s is used by %s", ports, used.owner)
+			}
+		}
+	}
+
+	if config.Listen != nil {
+		if port, err := listenPort(*config.Listen); err == nil {
+			checkUdp("listen", portRange{start: port, end: port})
+		}
+	}
+	for _, ports := range parsePortHopping(portHopping) {
+		checkUdp("portHopping", ports)
+	}
+	if config.TrafficStats != nil && config.TrafficStats.Listen != nil {
+		if port, err := listenPort(*config.TrafficStats.Listen); err == nil {
+			if name, exist := tcpUsed[port]; exist {
+				addError("trafficStats.listen", "tcp port %d is used by %s", port, name)
+			}
+		}
+	}
+	return nil
+}
+
+// portRange the inclusive range of a port hopping, a single port starts and ends with it
+type portRange struct {
+	start int64
+	end   int64
+}
+
+func (p portRange) String() string {
+	if p.start == p.end {
+		return strconv.FormatInt(p.start, 10)
+	}
+	return fmt.Sprintf("%d-%d", p.start, p.end)
+}
+
+func (p portRange) overlaps(other portRange) bool {
+	return p.start <= other.end && other.start <= p.end
+}
+
+// parsePortHopping e.g. 20000-30000,40000, the format is checked when it is saved so the invalid parts are skipped
+func parsePortHopping(portHopping string) []portRange {
+	var ranges []portRange
+	for _, part := range strings.Split(portHopping, ",") {
+		bounds := strings.SplitN(strings.TrimSpace(part), "-", 2)
+		start, err := strconv.ParseInt(bounds[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		end := start
+		if len(bounds) == 2 {
+			if end, err = strconv.ParseInt(bounds[1], 10, 64); err != nil {
+				continue
+			}
+		}
+		if start > end {
+			start, end = end, start
+		}
+		ranges = append(ranges, portRange{start: start, end: end})
+	}
+	return ranges
+}
+
+func listenPort(listen string) (int64, error) {
+	_, port, err := net.SplitHostPort(listen)
+	if err != nil {
+		return 0, err
+	}
+	value, err := strconv.ParseInt(port, 10, 64)
+	if err != nil || value <= 0 || value > 65535 {
+		return 0, fmt.Errorf("port %s is invalid", port)
+	}
+	return value, nil
+}
+
+func validateHysteria2Obfs(config bo.Hysteria2ServerConfig, addError func(string, string, ...any)) {
+	if config.Obfs != nil && config.Obfs.Type != nil && *config.Obfs.Type != "salamander" {
+		addError("obfs.type", "obfs type %s is not supported", *config.Obfs.Type)
+	}
+	masquerade := config.Masquerade
+	if masquerade == nil {
+		return
+	}
+	if masquerade.Type != nil && *masquerade.Type != "" {
+		required := map[string]bool{
+			"file":   masquerade.File != nil,
+			"proxy":  masquerade.Proxy != nil,
+			"string": masquerade.String != nil,
+		}
+		if set, ok := required[*masquerade.Type]; !ok {
+			addError("masquerade.type", "masquerade type %s is not supported", *masquerade.Type)
+		} else if !set {
+			addError("masquerade."+*masquerade.Type, "required by the masquerade type %s", *masquerade.Type)
+		}
+		// an obfuscated listener does not answer http/3, only the tcp listeners can serve the masquerade
+		if config.Obfs != nil &&
+			(masquerade.ListenHTTP == nil || *masquerade.ListenHTTP == "") &&
+			(masquerade.ListenHTTPS == nil || *masquerade.ListenHTTPS == "") {
+			addError("masquerade", "masquerade is unreachable when obfs is enabled, set listenHTTP or listenHTTPS")
+		}
+	}
+}
+
+// validateHysteria2Acl every rule must use a built-in outbound or one of the outbounds. The acl file is only read
+// inside bin/acl/ and its lines are never echoed, the errors name the line number only. A file outside the directory
+// is left to hysteria2.
+func validateHysteria2Acl(config bo.Hysteria2ServerConfig, addError func(string, string, ...any)) {
+	outbounds := map[string]bool{"direct": true, "reject": true, "default": true}
+	defined := map[string]bool{}
+	for i, item := range config.Outbounds {
+		if item.Name == nil {
+			continue
+		}
+		name := strings.ToLower(*item.Name)
+		if defined[name] {
+			addError(fmt.Sprintf("outbounds[%d].name", i), "outbound %s is duplicated", *item.Name)
+		}
+		defined[name] = true
+		outbounds[name] = true
+	}
+	if config.ACL == nil {
+		return
+	}
+
+	// validateRule the rule is only named in the errors when it is inline, echo is false for the lines of the file
+	validateRule := func(field string, rule string, echo bool) {
+		if i := strings.Index(rule, "#"); i >= 0 {
+			rule = rule[:i]
+		}
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			return
+		}
+		i := strings.Index(rule, "(")
+		if i <= 0 || !strings.HasSuffix(rule, ")") {
+			if echo {
+				addError(field, "rule %s is invalid", rule)
+			} else {
+				addError(field, "rule is invalid")
+			}
+			return
+		}
+		name := strings.ToLower(strings.TrimSpace(rule[:i]))
+		if !outbounds[name] {
+			if echo {
+				addError(field, "outbound %s is not defined", name)
+			} else {
+				addError(field, "outbound is not defined")
+			}
+		}
+	}
+	for i, rule := range config.ACL.Inline {
+		validateRule(fmt.Sprintf("acl.inline[%d]", i), rule, true)
+	}
+	if config.ACL.File != nil && *config.ACL.File != "" {
+		path, err := aclFilePath(*config.ACL.File)
+		if errors.Is(err, errAclFileOutside) {
+			return
+		}
+		if err != nil {
+			addError("acl.file", "%v", err)
+			return
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			addError("acl.file", "file %s not exist", *config.ACL.File)
+			return
+		}
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for line := 1; scanner.Scan(); line++ {
+			validateRule(fmt.Sprintf("acl.file:%d", line), scanner.Text(), false)
+		}
+	}
+}
+
+// errAclFileOutside the acl file is not inside bin/acl/, it is saved as is but its content is not validated
+var errAclFileOutside = fmt.Errorf("acl file is outside %s", constant.Hysteria2AclDir)
+
+// aclFilePath the real path of the acl file, only a file inside bin/acl/ is read so the validation cannot be used to
+// probe other files
+func aclFilePath(file string) (string, error) {
+	dir, err := filepath.Abs(constant.Hysteria2AclDir)
+	if err != nil {
+		return "", errAclFileOutside
+	}
+	path, err := filepath.Abs(file)
+	if err != nil || !strings.HasPrefix(path, dir+string(filepath.Separator)) {
+		return "", errAclFileOutside
+	}
+	// a symlink inside the directory must not lead out of it
+	if dir, err = filepath.EvalSymlinks(dir); err != nil {
+		return "", errAclFileOutside
+	}
+	if path, err = filepath.EvalSymlinks(path); err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("file %s not exist", file)
+		}
+		return "", errAclFileOutside
+	}
+	if !strings.HasPrefix(path, dir+string(filepath.Separator)) {
+		return "", errAclFileOutside
+	}
+	if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+		return "", fmt.Errorf("file %s is not a regular file", file)
+	}
+	return path, nil
+}
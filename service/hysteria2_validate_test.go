@@ -0,0 +1,199 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"h-ui/dao"
+	"h-ui/model/bo"
+	"h-ui/model/constant"
+	"h-ui/model/entity"
+)
+
+func hysteria2TestConfig(listen string, apiListen string) bo.Hysteria2ServerConfig {
+	return bo.Hysteria2ServerConfig{
+		Listen:       &listen,
+		TrafficStats: &bo.ServerConfigTrafficStats{Listen: &apiListen},
+	}
+}
+
+func saveTestInbound(t *testing.T, name string, config string, portHopping string) int64 {
+	var enable int64 = 1
+	id, err := dao.SaveInbound(entity.Inbound{Name: &name, Config: &config, PortHopping: &portHopping, Enable: &enable})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestValidateHysteria2PortConflict(t *testing.T) {
+	initTestDb(t)
+	if err := dao.UpdateConfig([]string{constant.Hysteria2Config}, map[string]interface{}{"value": "listen: :443\ntrafficStats:\n  listen: :7653\n"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := dao.UpdateConfig([]string{constant.Hysteria2ConfigPortHopping}, map[string]interface{}{"value": "20000-30000"}); err != nil {
+		t.Fatal(err)
+	}
+	inboundId := saveTestInbound(t, "inbound1", "listen: :8443\ntrafficStats:\n  listen: :7654\n", "40000-41000,45000")
+
+	tests := []struct {
+		name        string
+		id          int64
+		config      bo.Hysteria2ServerConfig
+		portHopping string
+		want        []string
+	}{
+		{"no conflict", inboundId, hysteria2TestConfig(":8443", ":7654"), "50000-51000", nil},
+		{"listen port of another instance", 0, hysteria2TestConfig(":8443", ":7653"), "20000-30000", []string{"listen: udp port 8443 is used by the listen port of inbound1"}},
+		{"traffic stats port of another instance", 0, hysteria2TestConfig(":443", ":7654"), "", []string{"trafficStats.listen: tcp port 7654 is used by inbound1"}},
+		{"listen port in the port hopping of another instance", 0, hysteria2TestConfig(":40500", ":7653"), "", []string{"listen: udp port 40500 is used by the port hopping of inbound1"}},
+		{"port hopping overlaps the port hopping of another instance", inboundId, hysteria2TestConfig(":8443", ":7654"), "29000-31000", []string{"portHopping: udp port 29000-31000 is used by the port hopping of hysteria2"}},
+		{"port hopping covers the listen port of another instance", 0, hysteria2TestConfig(":443", ":7653"), "8000-9000,44000-46000", []string{"portHopping: udp port 8000-9000 is used by the listen port of inbound1", "portHopping: udp port 44000-46000 is used by the port hopping of inbound1"}},
+		{"own ports are no conflict", inboundId, hysteria2TestConfig(":8443", ":7654"), "40000-41000,8443", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			addError := func(field string, format string, a ...any) {
+				got = append(got, field+": "+fmt.Sprintf(format, a...))
+			}
+			if err := validateHysteria2PortConflict(tt.id, tt.config, tt.portHopping, addError); err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateHysteria2PortHopping(t *testing.T) {
+	initTestDb(t)
+	if err := dao.UpdateConfig([]string{constant.Hysteria2Config}, map[string]interface{}{"value": "listen: :443\n"}); err != nil {
+		t.Fatal(err)
+	}
+	inboundId := saveTestInbound(t, "inbound1", "listen: :443\n", "")
+
+	// the listen port conflict exists already, only the port hopping is reported
+	fieldErrors, err := ValidateHysteria2PortHopping(inboundId, "400-500")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fieldErrors) != 1 || fieldErrors[0].Field != "portHopping" {
+		t.Fatalf("got %+v, want one port hopping error", fieldErrors)
+	}
+	if fieldErrors, err = ValidateHysteria2PortHopping(inboundId, "600-700"); err != nil || len(fieldErrors) != 0 {
+		t.Fatalf("got %+v %v, want no error", fieldErrors, err)
+	}
+}
+
+func TestParsePortHopping(t *testing.T) {
+	got := parsePortHopping("20000-30000, 40000,500-100,x,1-y")
+	want := []portRange{{20000, 30000}, {40000, 40000}, {100, 500}}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestValidateHysteria2AclFile(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+	if err = os.MkdirAll(constant.Hysteria2AclDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(constant.Hysteria2AclDir+"acl.txt", []byte("# rules\ndirect(all)\nsecret-line\nproxy(1.1.1.1)\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Mkdir(constant.Hysteria2AclDir+"sub", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile("outside.txt", []byte("secret-line\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Symlink(filepath.Join(dir, "outside.txt"), constant.Hysteria2AclDir+"link.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{constant.Hysteria2AclDir + "acl.txt", []string{"acl.file:3: rule is invalid", "acl.file:4: outbound is not defined"}},
+		// a file outside the directory is neither read nor rejected
+		{"outside.txt", nil},
+		{constant.Hysteria2AclDir + "../../outside.txt", nil},
+		{constant.Hysteria2AclDir + "link.txt", nil},
+		{"missing.txt", nil},
+		{"/etc/hysteria/acl.txt", nil},
+		{constant.Hysteria2AclDir + "sub", []string{"acl.file: file bin/acl/sub is not a regular file"}},
+		{constant.Hysteria2AclDir + "missing.txt", []string{"acl.file: file bin/acl/missing.txt not exist"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			var config bo.Hysteria2ServerConfig
+			if err := yaml.Unmarshal([]byte(fmt.Sprintf("acl:\n  file: %s\n", tt.file)), &config); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			addError := func(field string, format string, a ...any) {
+				got = append(got, field+": "+fmt.Sprintf(format, a...))
+			}
+			validateHysteria2Acl(config, addError)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+			// the content of the file is never part of an error
+			if strings.Contains(strings.Join(got, "\n"), "secret-line") {
+				t.Fatalf("the errors echo the file: %q", got)
+			}
+		})
+	}
+}
+
+func TestValidateHysteria2Obfs(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   []string
+	}{
+		{"masquerade without obfs", "masquerade:\n  type: string\n  string:\n    content: hi\n", nil},
+		{"obfs without masquerade", "obfs:\n  type: salamander\n", nil},
+		{"obfs with an unreachable masquerade", "obfs:\n  type: salamander\nmasquerade:\n  type: string\n  string:\n    content: hi\n",
+			[]string{"masquerade: masquerade is unreachable when obfs is enabled, set listenHTTP or listenHTTPS"}},
+		{"obfs with a tcp masquerade", "obfs:\n  type: salamander\nmasquerade:\n  type: string\n  string:\n    content: hi\n  listenHTTPS: :443\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var config bo.Hysteria2ServerConfig
+			if err := yaml.Unmarshal([]byte(tt.config), &config); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			validateHysteria2Obfs(config, func(field string, format string, args ...any) {
+				got = append(got, field+": "+fmt.Sprintf(format, args...))
+			})
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
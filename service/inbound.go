@@ -0,0 +1,432 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	"h-ui/dao"
+	"h-ui/model/bo"
+	"h-ui/model/constant"
+	"h-ui/model/dto"
+	"h-ui/model/entity"
+	"h-ui/proxy"
+	"os"
+)
+
+// hysteria2Instance a hysteria2 process with its config, the id of the one configured by HYSTERIA2_CONFIG is 0
+type hysteria2Instance struct {
+	id          int64
+	name        string // HYSTERIA2_CONFIG_REMARK or the inbound name, may be empty
+	config      bo.Hysteria2ServerConfig
+	portHopping string
+	process     *proxy.Hysteria2Process
+}
+
+// hysteria2UserApi the calls the panel makes on the traffic stats api of every instance
+type hysteria2UserApi interface {
+	ListUsers(clear bool, secret string) (map[string]bo.Hysteria2UserTraffic, error)
+	KickUsers(keys []string, secret string) error
+	OnlineUsers(secret string) (map[string]int64, error)
+}
+
+// hysteria2InstanceApi the traffic stats api of a running instance
+type hysteria2InstanceApi struct {
+	name string
+	api  hysteria2UserApi
+}
+
+// displayName the name the clients see
+func (h hysteria2Instance) displayName() string {
+	if h.name == "" {
+		return "hysteria2"
+	}
+	return h.name
+}
+
+func (h hysteria2Instance) api() (*proxy.Hysteria2Api, error) {
+	if h.config.TrafficStats == nil || h.config.TrafficStats.Listen == nil {
+		errMsg := fmt.Sprintf("%s Traffic Stats API (HTTP) Listen is nil", h.displayName())
+		logrus.Errorf(errMsg)
+		return nil, errors.New(errMsg)
+	}
+	apiPort, err := listenPort(*h.config.TrafficStats.Listen)
+	if err != nil {
+		errMsg := fmt.Sprintf("apiPort: %s is invalid", *h.config.TrafficStats.Listen)
+		logrus.Errorf(errMsg)
+		return nil, errors.New(errMsg)
+	}
+	return proxy.NewHysteria2InstanceApi(h.process, apiPort), nil
+}
+
+// listRunningHysteria2Api the api of every running instance, an instance without a usable api is logged and skipped,
+// the number of skipped instances is returned with them
+func listRunningHysteria2Api() ([]hysteria2InstanceApi, int, error) {
+	instances, err := listRunningHysteria2Instance()
+	if err != nil {
+		return nil, 0, err
+	}
+	var apis []hysteria2InstanceApi
+	skipped := 0
+	for _, instance := range instances {
+		hysteria2Api, err := instance.api()
+		if err != nil {
+			skipped++
+			continue
+		}
+		apis = append(apis, hysteria2InstanceApi{name: instance.displayName(), api: hysteria2Api})
+	}
+	return apis, skipped, nil
+}
+
+func primaryHysteria2Instance() (hysteria2Instance, error) {
+	hysteria2Config, err := GetHysteria2Config()
+	if err != nil {
+		return hysteria2Instance{}, err
+	}
+	configs, err := dao.ListConfig("key in ?", []string{constant.Hysteria2ConfigRemark, constant.Hysteria2ConfigPortHopping})
+	if err != nil {
+		return hysteria2Instance{}, err
+	}
+	instance := hysteria2Instance{config: hysteria2Config, process: proxy.NewHysteria2Instance()}
+	for _, item := range configs {
+		if *item.Key == constant.Hysteria2ConfigRemark {
+			instance.name = *item.Value
+		} else if *item.Key == constant.Hysteria2ConfigPortHopping {
+			instance.portHopping = *item.Value
+		}
+	}
+	return instance, nil
+}
+
+func inboundHysteria2Instance(inbound entity.Inbound) (hysteria2Instance, error) {
+	var hysteria2Config bo.Hysteria2ServerConfig
+	if err := yaml.Unmarshal([]byte(*inbound.Config), &hysteria2Config); err != nil {
+		logrus.Errorf("unmarshal inbound %s config err: %v", *inbound.Name, err)
+		return hysteria2Instance{}, fmt.Errorf("inbound %s config is invalid", *inbound.Name)
+	}
+	return hysteria2Instance{
+		id:          *inbound.Id,
+		name:        *inbound.Name,
+		config:      hysteria2Config,
+		portHopping: *inbound.PortHopping,
+		process:     proxy.NewHysteria2InboundInstance(*inbound.Id),
+	}, nil
+}
+
+// listHysteria2Instance the hysteria2 configured by HYSTERIA2_CONFIG if it is configured and every enabled inbound,
+// these are offered to the clients
+func listHysteria2Instance() ([]hysteria2Instance, error) {
+	var instances []hysteria2Instance
+	primary, err := primaryHysteria2Instance()
+	if err != nil {
+		return nil, err
+	}
+	if primary.config.Listen != nil && *primary.config.Listen != "" {
+		instances = append(instances, primary)
+	}
+	inbounds, err := dao.ListInbound("enable = 1")
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range inbounds {
+		instance, err := inboundHysteria2Instance(item)
+		if err != nil {
+			continue
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
+// listRunningHysteria2Instance the instances whose process is running, the traffic and the online users are read from them
+func listRunningHysteria2Instance() ([]hysteria2Instance, error) {
+	var instances []hysteria2Instance
+	if Hysteria2IsRunning() {
+		primary, err := primaryHysteria2Instance()
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, primary)
+	}
+	processes := proxy.ListHysteria2InboundInstance()
+	var ids []int64
+	for id, process := range processes {
+		if process.IsRunning() {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return instances, nil
+	}
+	inbounds, err := dao.ListInbound("id in ?", ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range inbounds {
+		instance, err := inboundHysteria2Instance(item)
+		if err != nil {
+			continue
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
+// Hysteria2AnyRunning whether the hysteria2 configured by HYSTERIA2_CONFIG or any inbound is running
+func Hysteria2AnyRunning() bool {
+	if Hysteria2IsRunning() {
+		return true
+	}
+	for _, process := range proxy.ListHysteria2InboundInstance() {
+		if process.IsRunning() {
+			return true
+		}
+	}
+	return false
+}
+
+// InitInbound start the enabled inbounds, an inbound that fails to start does not keep the panel from starting
+func InitInbound() {
+	inbounds, err := dao.ListInbound("enable = 1")
+	if err != nil {
+		return
+	}
+	for _, item := range inbounds {
+		if err = startInbound(item); err != nil {
+			logrus.Errorf("start inbound %s err: %v", *item.Name, err)
+		}
+	}
+}
+
+func PageInbound(inboundPageDto dto.InboundPageDto) ([]entity.Inbound, int64, error) {
+	return dao.PageInbound(inboundPageDto)
+}
+
+func ListInbound() ([]entity.Inbound, error) {
+	return dao.ListInbound(nil, nil)
+}
+
+func GetInbound(id int64) (entity.Inbound, error) {
+	return dao.GetInbound("id = ?", id)
+}
+
+func GetInboundConfig(inbound entity.Inbound) (bo.Hysteria2ServerConfig, error) {
+	instance, err := inboundHysteria2Instance(inbound)
+	if err != nil {
+		return bo.Hysteria2ServerConfig{}, err
+	}
+	return instance.config, nil
+}
+
+func ExistInboundName(name string, id int64) bool {
+	var err error
+	if id != 0 {
+		_, err = dao.GetInbound("name = ? and id != ?", name, id)
+	} else {
+		_, err = dao.GetInbound("name = ?", name)
+	}
+	return err == nil
+}
+
+func InboundIsRunning(id int64) bool {
+	return proxy.NewHysteria2InboundInstance(id).IsRunning()
+}
+
+// SaveInbound the inbound is started right away when it is enabled
+func SaveInbound(name string, hysteria2ServerConfig bo.Hysteria2ServerConfig, portHopping string, enable int64) error {
+	if err := setHysteria2Auth(&hysteria2ServerConfig); err != nil {
+		return err
+	}
+	config, err := yaml.Marshal(&hysteria2ServerConfig)
+	if err != nil {
+		logrus.Errorf("marshal inbound config err: %v", err)
+		return errors.New("marshal inbound config err")
+	}
+	configStr := string(config)
+	id, err := dao.SaveInbound(entity.Inbound{
+		Name:        &name,
+		Config:      &configStr,
+		PortHopping: &portHopping,
+		Enable:      &enable,
+	})
+	if err != nil {
+		return err
+	}
+	if enable == 1 {
+		inbound, err := dao.GetInbound("id = ?", id)
+		if err != nil {
+			return err
+		}
+		if err = startInbound(inbound); err != nil {
+			_ = dao.UpdateInbound([]int64{id}, map[string]interface{}{"enable": 0})
+			return err
+		}
+	}
+	return resetPortHopping(portHopping != "")
+}
+
+// UpdateInbound a running inbound is restarted with the new config, when it does not come up the previous config
+// is restored and the inbound restarted with it
+func UpdateInbound(id int64, name *string, hysteria2ServerConfig *bo.Hysteria2ServerConfig, portHopping *string, enable *int64) error {
+	previous, err := dao.GetInbound("id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	updates := map[string]interface{}{}
+	if name != nil && *name != "" {
+		updates["name"] = *name
+	}
+	if hysteria2ServerConfig != nil {
+		if err = setHysteria2Auth(hysteria2ServerConfig); err != nil {
+			return err
+		}
+		config, err := yaml.Marshal(hysteria2ServerConfig)
+		if err != nil {
+			logrus.Errorf("marshal inbound config err: %v", err)
+			return errors.New("marshal inbound config err")
+		}
+		updates["config"] = string(config)
+	}
+	if portHopping != nil {
+		updates["port_hopping"] = *portHopping
+	}
+	if enable != nil {
+		updates["enable"] = *enable
+	}
+
+	// stopped before the config is replaced, the traffic is read from the api port it is running with
+	running := InboundIsRunning(id)
+	configChanged := updates["config"] != nil && updates["config"] != *previous.Config
+	if running && (configChanged || (enable != nil && *enable == 0)) {
+		if err = stopInbound(id); err != nil {
+			return err
+		}
+	}
+	if err = dao.UpdateInbound([]int64{id}, updates); err != nil {
+		return err
+	}
+
+	inbound, err := dao.GetInbound("id = ?", id)
+	if err != nil {
+		return err
+	}
+	needResetPortHopping := *previous.PortHopping != "" || *inbound.PortHopping != ""
+	if *inbound.Enable == 0 || (running && !configChanged) {
+		return resetPortHopping(needResetPortHopping)
+	}
+
+	startErr := startInbound(inbound)
+	if startErr != nil && running {
+		if err = dao.UpdateInbound([]int64{id}, map[string]interface{}{"config": *previous.Config}); err != nil {
+			return fmt.Errorf("%v\nthe previous config could not be restored: %v", startErr, err)
+		}
+		inbound.Config = previous.Config
+		if err = startInbound(inbound); err != nil {
+			return fmt.Errorf("%v\nthe previous config is restored but the inbound failed to start with it: %v", startErr, err)
+		}
+		return fmt.Errorf("%v\nthe previous config is restored", startErr)
+	}
+	if startErr != nil {
+		_ = dao.UpdateInbound([]int64{id}, map[string]interface{}{"enable": *previous.Enable})
+		return startErr
+	}
+	return resetPortHopping(needResetPortHopping)
+}
+
+// DeleteInbound the inbounds are stopped first
+func DeleteInbound(ids []int64) error {
+	inbounds, err := dao.ListInbound("id in ?", ids)
+	if err != nil {
+		return err
+	}
+	needResetPortHopping := false
+	for _, item := range inbounds {
+		if InboundIsRunning(*item.Id) {
+			if err = stopInbound(*item.Id); err != nil {
+				return err
+			}
+		}
+		needResetPortHopping = needResetPortHopping || *item.PortHopping != ""
+	}
+	if err = dao.DeleteInbound(ids); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		proxy.RemoveHysteria2InboundInstance(id)
+	}
+	return resetPortHopping(needResetPortHopping)
+}
+
+// resetPortHopping the rules are only touched when an inbound with port hopping changed,
+// port hopping is not supported on every system
+func resetPortHopping(needResetPortHopping bool) error {
+	if !needResetPortHopping {
+		return nil
+	}
+	return InitPortHopping()
+}
+
+// startInbound the auth url and the traffic stats secret are refreshed in the file the inbound is started with,
+// the panel port or the jwt secret may have changed since it was saved
+func startInbound(inbound entity.Inbound) error {
+	instance, err := inboundHysteria2Instance(inbound)
+	if err != nil {
+		return err
+	}
+	if instance.config.Listen == nil || *instance.config.Listen == "" {
+		return fmt.Errorf("inbound %s config is empty", *inbound.Name)
+	}
+	if err = setHysteria2Auth(&instance.config); err != nil {
+		return err
+	}
+	config, err := yaml.Marshal(&instance.config)
+	if err != nil {
+		logrus.Errorf("marshal inbound config err: %v", err)
+		return errors.New("marshal inbound config err")
+	}
+	if err = os.WriteFile(instance.process.ConfigPath(), config, 0644); err != nil {
+		logrus.Errorf("write inbound %s config file err: %v", *inbound.Name, err)
+		return errors.New("write inbound config file err")
+	}
+	if err = instance.process.StartHysteria2(); err != nil {
+		return err
+	}
+	if err = waitHysteria2Ready(instance, hysteria2Timeout(constant.Hysteria2StartTimeout)); err != nil {
+		_ = instance.process.StopHysteria2(0)
+		return err
+	}
+	return nil
+}
+
+// stopInbound the traffic since the last sync is saved before the sessions are closed
+func stopInbound(id int64) error {
+	if jwtSecretConfig, err := dao.GetConfig("key = ?", constant.JwtSecret); err == nil {
+		_ = flushAccountTraffic(*jwtSecretConfig.Value)
+	}
+	return proxy.NewHysteria2InboundInstance(id).StopHysteria2(hysteria2Timeout(constant.Hysteria2StopTimeout))
+}
+
+// StopInbounds stop every running inbound
+func StopInbounds() error {
+	for id, process := range proxy.ListHysteria2InboundInstance() {
+		if !process.IsRunning() {
+			continue
+		}
+		if err := stopInbound(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReleaseInbounds leave the inbounds running when the panel exits, like hysteria2 itself
+func ReleaseInbounds() error {
+	for _, process := range proxy.ListHysteria2InboundInstance() {
+		if err := process.Release(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
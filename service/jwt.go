@@ -91,3 +91,12 @@ func GetToken(c *gin.Context) string {
 	}
 	return strings.SplitN(tokenStr, " ", 2)[1]
 }
+
+// GetUsername the username of the request, empty when the token is invalid
+func GetUsername(c *gin.Context) string {
+	claims, err := ParseToken(GetToken(c))
+	if err != nil {
+		return ""
+	}
+	return claims.AccountBo.Username
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"github.com/sirupsen/logrus"
+	"h-ui/dao"
+	"h-ui/model/constant"
+	"h-ui/util"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var trafficSyncStats struct {
+	mutex        sync.Mutex
+	count        int64
+	errors       int64
+	lastDuration time.Duration
+	lastTime     int64 // unix seconds
+}
+
+// recordTrafficSync the errors of one run of saveAccountTraffic, e.g. an instance whose api did not respond
+func recordTrafficSync(duration time.Duration, errors int64) {
+	trafficSyncStats.mutex.Lock()
+	defer trafficSyncStats.mutex.Unlock()
+	trafficSyncStats.count++
+	trafficSyncStats.errors += errors
+	trafficSyncStats.lastDuration = duration
+	trafficSyncStats.lastTime = time.Now().Unix()
+}
+
+// ListMetrics the metrics scraped by prometheus, the per account metrics are only labelled by username
+// when METRICS_ACCOUNT_ENABLE is on, otherwise they are summed to keep the cardinality low
+func ListMetrics() ([]util.PromMetric, error) {
+	var metrics []util.PromMetric
+	gauge := func(name string, help string, value float64) {
+		metrics = append(metrics, util.PromMetric{Name: name, Help: help, Type: "gauge", Samples: []util.PromSample{{Value: value}}})
+	}
+
+	systemMonitorVo, err := MonitorSystem()
+	if err != nil {
+		return nil, err
+	}
+	gauge("hui_system_cpu_percent", "CPU usage in percent", systemMonitorVo.CpuPercent)
+	gauge("hui_system_memory_percent", "Memory usage in percent", systemMonitorVo.MemPercent)
+	gauge("hui_system_disk_percent", "Disk usage in percent", systemMonitorVo.DiskPercent)
+	gauge("hui_system_load1", "Load average over 1 minute", systemMonitorVo.Load1)
+	gauge("hui_system_load5", "Load average over 5 minutes", systemMonitorVo.Load5)
+	gauge("hui_system_load15", "Load average over 15 minutes", systemMonitorVo.Load15)
+	gauge("hui_system_network_receive_bytes_per_second", "Bytes received by every interface except loopback", systemMonitorVo.NetRecv)
+	gauge("hui_system_network_transmit_bytes_per_second", "Bytes sent by every interface except loopback", systemMonitorVo.NetSent)
+	gauge("hui_system_tcp_sockets", "TCP sockets in use", float64(systemMonitorVo.TcpSockets))
+	gauge("hui_system_udp_sockets", "UDP sockets in use", float64(systemMonitorVo.UdpSockets))
+	metrics = append(metrics, util.PromMetric{Name: "hui_system_udp_rcvbuf_errors_total",
+		Help: "UDP datagrams dropped because a receive buffer was full", Type: "counter",
+		Samples: []util.PromSample{{Value: float64(systemMonitorVo.UdpRcvbufErrors)}}})
+	interfaceMetrics := []util.PromMetric{
+		{Name: "hui_interface_receive_bytes_per_second", Help: "Bytes received by the interface", Type: "gauge"},
+		{Name: "hui_interface_transmit_bytes_per_second", Help: "Bytes sent by the interface", Type: "gauge"},
+		{Name: "hui_interface_receive_packets_per_second", Help: "Packets received by the interface", Type: "gauge"},
+		{Name: "hui_interface_transmit_packets_per_second", Help: "Packets sent by the interface", Type: "gauge"},
+	}
+	for _, item := range systemMonitorVo.Interfaces {
+		labels := []util.PromLabel{{Name: "interface", Value: item.Name}}
+		for i, value := range []float64{item.RxBytes, item.TxBytes, item.RxPackets, item.TxPackets} {
+			interfaceMetrics[i].Samples = append(interfaceMetrics[i].Samples, util.PromSample{Labels: labels, Value: value})
+		}
+	}
+	metrics = append(metrics, interfaceMetrics...)
+
+	hysteria2Metrics, online := listHysteria2Metrics()
+	metrics = append(metrics, hysteria2Metrics...)
+
+	trafficSyncStats.mutex.Lock()
+	metrics = append(metrics,
+		util.PromMetric{Name: "hui_traffic_sync_total", Help: "Runs of the traffic sync", Type: "counter",
+			Samples: []util.PromSample{{Value: float64(trafficSyncStats.count)}}},
+		util.PromMetric{Name: "hui_traffic_sync_errors_total", Help: "Errors of the traffic sync", Type: "counter",
+			Samples: []util.PromSample{{Value: float64(trafficSyncStats.errors)}}})
+	if trafficSyncStats.count > 0 {
+		gauge("hui_traffic_sync_duration_seconds", "Duration of the last traffic sync", trafficSyncStats.lastDuration.Seconds())
+		gauge("hui_traffic_sync_last_timestamp_seconds", "Time of the last traffic sync", float64(trafficSyncStats.lastTime))
+	}
+	trafficSyncStats.mutex.Unlock()
+
+	accountMetrics, err := listAccountMetrics(online)
+	if err != nil {
+		return nil, err
+	}
+	return append(metrics, accountMetrics...), nil
+}
+
+// listHysteria2Metrics when hysteria2 cannot be monitored it is reported down, the scrape keeps the other metrics.
+// online is whether any user is online.
+func listHysteria2Metrics() ([]util.PromMetric, bool) {
+	up := util.PromMetric{Name: "hui_hysteria2_up", Help: "Whether the hysteria2 instance is running", Type: "gauge"}
+	restarts := util.PromMetric{Name: "hui_hysteria2_restarts_total", Help: "Automatic restarts of the hysteria2 instance after a crash", Type: "counter"}
+	addInstance := func(id int64, name string, running bool, restartCount int64) {
+		labels := []util.PromLabel{{Name: "inbound_id", Value: strconv.FormatInt(id, 10)}, {Name: "inbound", Value: name}}
+		value := 0.0
+		if running {
+			value = 1
+		}
+		up.Samples = append(up.Samples, util.PromSample{Labels: labels, Value: value})
+		restarts.Samples = append(restarts.Samples, util.PromSample{Labels: labels, Value: float64(restartCount)})
+	}
+
+	hysteria2MonitorVo, err := MonitorHysteria2()
+	if err != nil {
+		logrus.Errorf("metrics monitor hysteria2 err: %v", err)
+		addInstance(0, "", false, 0)
+		return []util.PromMetric{up}, false
+	}
+	// the hysteria2 configured by HYSTERIA2_CONFIG is the inbound 0 without a name
+	addInstance(0, "", hysteria2MonitorVo.Running, hysteria2MonitorVo.RestartCount)
+	for _, item := range hysteria2MonitorVo.Inbounds {
+		addInstance(item.Id, item.Name, item.Running, item.RestartCount)
+	}
+	return []util.PromMetric{
+		{Name: "hui_hysteria2_online_users", Help: "Online users summed over every instance", Type: "gauge",
+			Samples: []util.PromSample{{Value: float64(hysteria2MonitorVo.UserTotal)}}},
+		{Name: "hui_hysteria2_online_devices", Help: "Online devices summed over every instance", Type: "gauge",
+			Samples: []util.PromSample{{Value: float64(hysteria2MonitorVo.DeviceTotal)}}},
+		up,
+		restarts,
+	}, hysteria2MonitorVo.UserTotal > 0
+}
+
+func listAccountMetrics(online bool) ([]util.PromMetric, error) {
+	accountEnable, err := dao.GetConfig("key = ?", constant.MetricsAccountEnable)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := dao.ListAccount("trashed_at = 0")
+	if err != nil {
+		return nil, err
+	}
+
+	if *accountEnable.Value != "1" {
+		var upload, download, quota int64
+		for _, item := range accounts {
+			upload += *item.Upload
+			download += *item.Download
+			if *item.Quota > 0 {
+				quota += *item.Quota
+			}
+		}
+		return []util.PromMetric{
+			{Name: "hui_accounts", Help: "Accounts not in the trash", Type: "gauge",
+				Samples: []util.PromSample{{Value: float64(len(accounts))}}},
+			// the reset of one account lowers the sum, so it is no counter
+			{Name: "hui_accounts_upload_bytes", Help: "Upload of every account since its last reset", Type: "gauge",
+				Samples: []util.PromSample{{Value: float64(upload)}}},
+			{Name: "hui_accounts_download_bytes", Help: "Download of every account since its last reset", Type: "gauge",
+				Samples: []util.PromSample{{Value: float64(download)}}},
+			{Name: "hui_accounts_quota_bytes", Help: "Quota of every account with a limited quota", Type: "gauge",
+				Samples: []util.PromSample{{Value: float64(quota)}}},
+		}, nil
+	}
+
+	onlineUsers := map[string]int64{}
+	if online {
+		if onlineUsers, err = Hysteria2Online(); err != nil {
+			return nil, err
+		}
+	}
+	upload := util.PromMetric{Name: "hui_account_upload_bytes_total", Help: "Upload of the account since its last reset", Type: "counter"}
+	download := util.PromMetric{Name: "hui_account_download_bytes_total", Help: "Download of the account since its last reset", Type: "counter"}
+	quota := util.PromMetric{Name: "hui_account_quota_bytes", Help: "Quota of the account, -1 is unlimited", Type: "gauge"}
+	devices := util.PromMetric{Name: "hui_account_online_devices", Help: "Online devices of the account", Type: "gauge"}
+	for _, item := range accounts {
+		labels := []util.PromLabel{{Name: "username", Value: *item.Username}}
+		upload.Samples = append(upload.Samples, util.PromSample{Labels: labels, Value: float64(*item.Upload)})
+		download.Samples = append(download.Samples, util.PromSample{Labels: labels, Value: float64(*item.Download)})
+		quota.Samples = append(quota.Samples, util.PromSample{Labels: labels, Value: float64(*item.Quota)})
+		devices.Samples = append(devices.Samples, util.PromSample{Labels: labels, Value: float64(onlineUsers[*item.Username])})
+	}
+	return []util.PromMetric{upload, download, quota, devices}, nil
+}
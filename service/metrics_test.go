@@ -0,0 +1,46 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"h-ui/dao"
+)
+
+func TestListHysteria2MetricsDown(t *testing.T) {
+	initTestDb(t)
+	// the inbounds cannot be listed, hysteria2 cannot be monitored
+	if err := dao.CloseSqliteDB(); err != nil {
+		t.Fatal(err)
+	}
+	metrics, online := listHysteria2Metrics()
+	if online {
+		t.Fatal("got online, want offline")
+	}
+	if len(metrics) != 1 || metrics[0].Name != "hui_hysteria2_up" || len(metrics[0].Samples) != 1 || metrics[0].Samples[0].Value != 0 {
+		t.Fatalf("got %+v, want hui_hysteria2_up 0", metrics)
+	}
+}
+
+func TestListAccountMetricsSum(t *testing.T) {
+	initTestDb(t)
+	saveTestAccount(t, "user01", map[string]interface{}{"quota": 100, "download": 10, "upload": 1})
+	saveTestAccount(t, "user02", map[string]interface{}{"quota": 50, "download": 20, "upload": 2})
+	saveTestAccount(t, "user03", map[string]interface{}{"download": 30, "upload": 3})
+	metrics, err := listAccountMetrics(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]float64{}
+	for _, metric := range metrics {
+		if len(metric.Samples) != 1 || len(metric.Samples[0].Labels) > 0 {
+			t.Fatalf("%s: got %+v, want one sample without labels", metric.Name, metric.Samples)
+		}
+		got[metric.Name] = metric.Samples[0].Value
+	}
+	// the unlimited accounts, the admin among them, are not part of the quota
+	want := map[string]float64{"hui_accounts": 4, "hui_accounts_upload_bytes": 6, "hui_accounts_download_bytes": 60, "hui_accounts_quota_bytes": 150}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -1,36 +1,22 @@
 package service
 
 import (
-	"errors"
 	"fmt"
+	"h-ui/dao"
 	"h-ui/model/constant"
 	"h-ui/model/vo"
+	"h-ui/proxy"
 	"h-ui/util"
 	"regexp"
 	"strings"
 )
 
+// MonitorSystem the latest sample of the background sampler, so it does not block on measuring the cpu
 func MonitorSystem() (vo.SystemMonitorVo, error) {
-	cpuPercent, err := util.GetCpuPercent()
-	if err != nil {
-		return vo.SystemMonitorVo{}, errors.New("cpu query failed")
-	}
-	memPercent, err := util.GetMemPercent()
-	if err != nil {
-		return vo.SystemMonitorVo{}, errors.New("mem query failed")
-	}
-	diskPercent, err := util.GetDiskPercent()
-	if err != nil {
-		return vo.SystemMonitorVo{}, errors.New("disk query failed")
-	}
-	return vo.SystemMonitorVo{
-		HUIVersion:  constant.Version,
-		CpuPercent:  cpuPercent,
-		MemPercent:  memPercent,
-		DiskPercent: diskPercent,
-	}, nil
+	return latestSystemMetrics()
 }
 
+// MonitorHysteria2 the online users and devices are summed over every running instance
 func MonitorHysteria2() (vo.Hysteria2MonitorVo, error) {
 	var hysteria2MonitorVo vo.Hysteria2MonitorVo
 	onlineUsers, err := Hysteria2Online()
@@ -58,7 +44,55 @@ func MonitorHysteria2() (vo.Hysteria2MonitorVo, error) {
 		}
 	}
 
-	running := Hysteria2IsRunning()
-	hysteria2MonitorVo.Running = running
+	hysteria2MonitorVo.Hysteria2ProcessMonitorVo = monitorHysteria2Process(0, proxy.NewHysteria2Instance())
+
+	inbounds, err := dao.ListInbound(nil, nil)
+	if err != nil {
+		return hysteria2MonitorVo, err
+	}
+	jwtSecretConfig, err := dao.GetConfig("key = ?", constant.JwtSecret)
+	if err != nil {
+		return hysteria2MonitorVo, err
+	}
+	hysteria2MonitorVo.Inbounds = make([]vo.InboundMonitorVo, 0, len(inbounds))
+	for _, item := range inbounds {
+		inboundMonitorVo := vo.InboundMonitorVo{
+			Id:   *item.Id,
+			Name: *item.Name,
+		}
+		if instance, err := inboundHysteria2Instance(item); err == nil {
+			inboundMonitorVo.Hysteria2ProcessMonitorVo = monitorHysteria2Process(instance.id, instance.process)
+			if hysteria2Api, err := instance.api(); err == nil && inboundMonitorVo.Running {
+				if users, err := hysteria2Api.OnlineUsers(*jwtSecretConfig.Value); err == nil {
+					inboundMonitorVo.UserTotal = int64(len(users))
+					for _, value := range users {
+						inboundMonitorVo.DeviceTotal += value
+					}
+				}
+			}
+		}
+		hysteria2MonitorVo.Inbounds = append(hysteria2MonitorVo.Inbounds, inboundMonitorVo)
+	}
 	return hysteria2MonitorVo, nil
 }
+
+func monitorHysteria2Process(id int64, hysteria2Process *proxy.Hysteria2Process) vo.Hysteria2ProcessMonitorVo {
+	var processMonitorVo vo.Hysteria2ProcessMonitorVo
+	processMonitorVo.Running = hysteria2Process.IsRunning()
+
+	stats := hysteria2Process.Stats()
+	processMonitorVo.RestartCount = stats.RestartCount
+	processMonitorVo.LastExitCode = stats.LastExitCode
+	processMonitorVo.LastCrashTime = stats.LastCrashTime
+
+	if history := ListHysteria2Metrics(id); len(history) > 0 {
+		metrics := history[len(history)-1]
+		processMonitorVo.CpuPercent = metrics.CpuPercent
+		processMonitorVo.Rss = metrics.Rss
+		processMonitorVo.Fds = metrics.Fds
+		processMonitorVo.Threads = metrics.Threads
+		processMonitorVo.Uptime = metrics.Uptime
+		processMonitorVo.UdpDrops = metrics.UdpDrops
+	}
+	return processMonitorVo
+}
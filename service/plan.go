@@ -0,0 +1,162 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"h-ui/dao"
+	"h-ui/model/dto"
+	"h-ui/model/entity"
+	"h-ui/util"
+	"time"
+)
+
+func PagePlan(planPageDto dto.PlanPageDto) ([]entity.Plan, int64, error) {
+	return dao.PagePlan(planPageDto)
+}
+
+func ListPlan() ([]entity.Plan, error) {
+	return dao.ListPlan(nil, nil)
+}
+
+func GetPlan(id int64) (entity.Plan, error) {
+	return dao.GetPlan("id = ?", id)
+}
+
+func SavePlan(plan entity.Plan) error {
+	_, err := dao.SavePlan(plan)
+	return err
+}
+
+// DeletePlan the accounts on the plan keep their current values
+func DeletePlan(ids []int64) error {
+	return dao.DeletePlan(ids)
+}
+
+func ExistPlanName(name string, id int64) bool {
+	var err error
+	if id != 0 {
+		_, err = dao.GetPlan("name = ? and id != ?", name, id)
+	} else {
+		_, err = dao.GetPlan("name = ?", name)
+	}
+	return err == nil
+}
+
+// UpdatePlan propagate copies quota, device limit and reset cycle onto every account on the plan
+func UpdatePlan(plan entity.Plan, propagate bool) error {
+	planUpdates := map[string]interface{}{}
+	if plan.Name != nil && *plan.Name != "" {
+		planUpdates["name"] = *plan.Name
+	}
+	if plan.Quota != nil {
+		planUpdates["quota"] = *plan.Quota
+	}
+	if plan.Duration != nil {
+		planUpdates["duration"] = *plan.Duration
+	}
+	if plan.DeviceNo != nil {
+		planUpdates["device_no"] = *plan.DeviceNo
+	}
+	if plan.TrafficMultiplier != nil {
+		planUpdates["traffic_multiplier"] = *plan.TrafficMultiplier
+	}
+	if plan.ResetCycle != nil {
+		planUpdates["reset_cycle"] = *plan.ResetCycle
+	}
+	if plan.ResetInterval != nil {
+		planUpdates["reset_interval"] = *plan.ResetInterval
+	}
+
+	accountUpdates := map[string]interface{}{}
+	if propagate {
+		current, err := dao.GetPlan("id = ?", *plan.Id)
+		if err != nil {
+			return err
+		}
+		if plan.Quota != nil {
+			current.Quota = plan.Quota
+		}
+		if plan.DeviceNo != nil {
+			current.DeviceNo = plan.DeviceNo
+		}
+		if plan.ResetCycle != nil {
+			current.ResetCycle = plan.ResetCycle
+		}
+		if plan.ResetInterval != nil {
+			current.ResetInterval = plan.ResetInterval
+		}
+		accountUpdates["quota"] = *current.Quota
+		accountUpdates["device_no"] = *current.DeviceNo
+		accountUpdates["reset_cycle"] = *current.ResetCycle
+		accountUpdates["reset_interval"] = *current.ResetInterval
+	}
+	return dao.UpdatePlanAndAccount(*plan.Id, planUpdates, accountUpdates)
+}
+
+// AssignPlan put the accounts on the plan and start a new period from now
+func AssignPlan(ids []int64, planId int64) error {
+	plan, err := dao.GetPlan("id = ?", planId)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	return dao.UpdateAccount(ids, map[string]interface{}{
+		"plan_id":        *plan.Id,
+		"quota":          *plan.Quota,
+		"device_no":      *plan.DeviceNo,
+		"expire_time":    now.AddDate(0, 0, int(*plan.Duration)).UnixMilli(),
+		"reset_cycle":    *plan.ResetCycle,
+		"reset_interval": *plan.ResetInterval,
+		"reset_anchor":   now.UnixMilli(),
+	})
+}
+
+// RenewAccount extend the expiry by the plan duration and add the plan quota to the quota the account has left, the
+// traffic is reset so the new quota is the unused quota plus the plan quota. An unlimited plan quota or account quota
+// leaves the account unlimited or on the plan quota
+func RenewAccount(ids []int64) error {
+	accounts, err := dao.ListAccount("id in ?", ids)
+	if err != nil {
+		return err
+	}
+	plans := map[int64]entity.Plan{}
+	for _, account := range accounts {
+		if *account.PlanId == 0 {
+			return fmt.Errorf("account %s is not on a plan", *account.Username)
+		}
+		if _, exist := plans[*account.PlanId]; !exist {
+			plan, err := dao.GetPlan("id = ?", *account.PlanId)
+			if err != nil {
+				return err
+			}
+			plans[*account.PlanId] = plan
+		}
+	}
+	if len(accounts) == 0 {
+		return errors.New("account not exist")
+	}
+
+	now := time.Now().UnixMilli()
+	updates := make(map[int64]map[string]interface{}, len(accounts))
+	for _, account := range accounts {
+		plan := plans[*account.PlanId]
+		base := *account.ExpireTime
+		if base < now {
+			base = now
+		}
+		quota := *plan.Quota
+		if quota >= 0 && *account.Quota > 0 {
+			if left := *account.Quota - util.BilledTraffic(*account.BillingMode, *account.Download, *account.Upload); left > 0 {
+				quota += left
+			}
+		}
+		updates[*account.Id] = map[string]interface{}{
+			"quota":       quota,
+			"download":    0,
+			"upload":      0,
+			"reset_at":    now,
+			"expire_time": time.UnixMilli(base).AddDate(0, 0, int(*plan.Duration)).UnixMilli(),
+		}
+	}
+	return dao.UpdateAccountEach(updates)
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"testing"
+
+	"h-ui/dao"
+	"h-ui/model/entity"
+)
+
+func TestRenewAccount(t *testing.T) {
+	initTestDb(t)
+	name := "monthly"
+	var quota int64 = 100
+	var duration int64 = 30
+	planId, err := dao.SavePlan(entity.Plan{Name: &name, Quota: &quota, Duration: &duration})
+	if err != nil {
+		t.Fatal(err)
+	}
+	left := saveTestAccount(t, "left", map[string]interface{}{"plan_id": planId, "quota": 50, "download": 20, "upload": 10})
+	over := saveTestAccount(t, "over", map[string]interface{}{"plan_id": planId, "quota": 50, "download": 60})
+	unlimited := saveTestAccount(t, "unlimited", map[string]interface{}{"plan_id": planId})
+
+	if err = RenewAccount([]int64{left, over, unlimited}); err != nil {
+		t.Fatal(err)
+	}
+	for id, want := range map[int64]int64{left: 120, over: 100, unlimited: 100} {
+		account, err := dao.GetAccount("id = ?", id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *account.Quota != want || *account.Download != 0 || *account.Upload != 0 || *account.ResetAt == 0 {
+			t.Errorf("account %s: quota %d download %d upload %d reset_at %d, want quota %d and the traffic reset",
+				*account.Username, *account.Quota, *account.Download, *account.Upload, *account.ResetAt, want)
+		}
+	}
+
+	if err = RenewAccount([]int64{left, saveTestAccount(t, "noplan", nil)}); err == nil {
+		t.Error("renewing an account that is not on a plan should fail")
+	}
+	account, err := dao.GetAccount("id = ?", left)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *account.Quota != 120 {
+		t.Errorf("a failed renewal changed the quota to %d", *account.Quota)
+	}
+}
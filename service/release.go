@@ -0,0 +1,46 @@
+package service
+
+import (
+	"h-ui/dao"
+	"h-ui/model/constant"
+	"h-ui/util"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// InitReleaseSource apply the release source configs to the github client
+func InitReleaseSource() error {
+	configs, err := dao.ListConfig("key in ?", []string{
+		constant.ReleaseGithubToken,
+		constant.ReleaseGithubUrl,
+		constant.ReleaseMirrorUrl,
+		constant.ReleaseCacheTtl})
+	if err != nil {
+		return err
+	}
+	releaseSource := util.ReleaseSource{CacheTTL: time.Hour}
+	for _, item := range configs {
+		if item.Value == nil {
+			continue
+		}
+		key := *item.Key
+		value := *item.Value
+		if key == constant.ReleaseGithubToken {
+			releaseSource.Token = value
+		} else if key == constant.ReleaseGithubUrl {
+			releaseSource.BaseURL = value
+		} else if key == constant.ReleaseMirrorUrl {
+			releaseSource.MirrorURL = value
+		} else if key == constant.ReleaseCacheTtl {
+			minutes, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || minutes < 0 {
+				logrus.Errorf("release cache ttl string conv int64 err: %v", err)
+				continue
+			}
+			releaseSource.CacheTTL = time.Duration(minutes) * time.Minute
+		}
+	}
+	return util.SetReleaseSource(releaseSource)
+}
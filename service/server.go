@@ -30,6 +30,9 @@ func StopServer() error {
 	if err := StopHysteria2(); err != nil {
 		return err
 	}
+	if err := StopInbounds(); err != nil {
+		return err
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"errors"
+	"github.com/sirupsen/logrus"
+	"h-ui/dao"
+	"h-ui/model/bo"
+	"h-ui/model/constant"
+	"h-ui/model/entity"
+	"h-ui/model/vo"
+	"h-ui/util"
+	"sync"
+	"time"
+)
+
+// systemMetricsMinuteRetention the minute buckets are kept for the 1h and 24h windows, the hour buckets for the 7d and 30d windows
+const (
+	systemMetricsMinuteRetention = 24 * time.Hour
+	systemMetricsHourRetention   = 30 * 24 * time.Hour
+)
+
+// systemMetrics the latest sample with the network throughput since the sample before it
+var systemMetrics struct {
+	mutex  sync.Mutex
+	last   bo.SystemMetrics
+	latest vo.SystemMonitorVo
+}
+
+// CronSampleSystemMetrics sample the system usage into the minute and hour buckets and drop the expired buckets
+func CronSampleSystemMetrics() {
+	sample, err := sampleSystemMetrics()
+	if err != nil {
+		return
+	}
+
+	bucket := entity.SystemMetrics{
+		CpuPercent:  &sample.CpuPercent,
+		MemPercent:  &sample.MemPercent,
+		DiskPercent: &sample.DiskPercent,
+		Load1:       &sample.Load1,
+		Load5:       &sample.Load5,
+		Load15:      &sample.Load15,
+		NetRecv:     &sample.NetRecv,
+		NetSent:     &sample.NetSent,
+	}
+	now := time.UnixMilli(sample.SampleTime)
+	minuteTime := now.Truncate(time.Minute).UnixMilli()
+	hourTime := time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 0, 0, 0, now.Location()).UnixMilli()
+	if err = dao.UpsertSystemMetrics(constant.SystemMetricsPeriodMinute, minuteTime, bucket); err != nil {
+		return
+	}
+	if err = dao.UpsertSystemMetrics(constant.SystemMetricsPeriodHour, hourTime, bucket); err != nil {
+		return
+	}
+
+	_ = dao.DeleteSystemMetrics("period = ? and period_time < ?",
+		constant.SystemMetricsPeriodMinute, now.Add(-systemMetricsMinuteRetention).UnixMilli())
+	_ = dao.DeleteSystemMetrics("period = ? and period_time < ?",
+		constant.SystemMetricsPeriodHour, now.Add(-systemMetricsHourRetention).UnixMilli())
+}
+
+// sampleSystemMetrics take a sample and remember it as the latest, the throughput of the first sample is 0
+func sampleSystemMetrics() (vo.SystemMonitorVo, error) {
+	sample, err := util.GetSystemMetrics()
+	if err != nil {
+		logrus.Errorf("%v", err)
+		return vo.SystemMonitorVo{}, errors.New("system metrics query failed")
+	}
+
+	systemMetrics.mutex.Lock()
+	defer systemMetrics.mutex.Unlock()
+	previous := systemMetrics.last
+	systemMetrics.last = sample
+
+	systemMonitorVo := vo.SystemMonitorVo{
+		HUIVersion:      constant.Version,
+		CpuPercent:      sample.CpuPercent,
+		MemPercent:      sample.MemPercent,
+		DiskPercent:     sample.DiskPercent,
+		Load1:           sample.Load1,
+		Load5:           sample.Load5,
+		Load15:          sample.Load15,
+		SampleTime:      sample.Time,
+		Interfaces:      make([]vo.InterfaceMonitorVo, 0, len(ingressInterfaces)),
+		TcpSockets:      sample.TcpSockets,
+		UdpSockets:      sample.UdpSockets,
+		UdpRcvbufErrors: sample.UdpRcvbufErrors,
+	}
+	seconds := float64(sample.Time-previous.Time) / 1000
+	// the counters start over when an interface goes away
+	rate := func(current uint64, last uint64) float64 {
+		if previous.Time == 0 || seconds <= 0 || current < last {
+			return 0
+		}
+		return float64(current-last) / seconds
+	}
+	systemMonitorVo.NetRecv = rate(sample.NetRecv, previous.NetRecv)
+	systemMonitorVo.NetSent = rate(sample.NetSent, previous.NetSent)
+	for _, name := range ingressInterfaces {
+		current, exist := sample.Interfaces[name]
+		if !exist {
+			continue
+		}
+		last := previous.Interfaces[name]
+		systemMonitorVo.Interfaces = append(systemMonitorVo.Interfaces, vo.InterfaceMonitorVo{
+			Name:      name,
+			RxBytes:   rate(current.BytesRecv, last.BytesRecv),
+			TxBytes:   rate(current.BytesSent, last.BytesSent),
+			RxPackets: rate(current.PacketsRecv, last.PacketsRecv),
+			TxPackets: rate(current.PacketsSent, last.PacketsSent),
+		})
+	}
+	systemMetrics.latest = systemMonitorVo
+	return systemMonitorVo, nil
+}
+
+// latestSystemMetrics the sample taken by the cron, before its first run a sample is taken now
+func latestSystemMetrics() (vo.SystemMonitorVo, error) {
+	systemMetrics.mutex.Lock()
+	if systemMetrics.last.Time > 0 {
+		defer systemMetrics.mutex.Unlock()
+		return systemMetrics.latest, nil
+	}
+	systemMetrics.mutex.Unlock()
+	return sampleSystemMetrics()
+}
+
+// ListSystemMetrics the buckets of the window, oldest first, the short windows use the minute buckets and the long ones the hour buckets
+func ListSystemMetrics(window string) ([]entity.SystemMetrics, error) {
+	period := constant.SystemMetricsPeriodMinute
+	var duration time.Duration
+	switch window {
+	case constant.SystemMetricsWindowHour:
+		duration = time.Hour
+	case constant.SystemMetricsWindowDay:
+		duration = 24 * time.Hour
+	case constant.SystemMetricsWindowWeek:
+		period = constant.SystemMetricsPeriodHour
+		duration = 7 * 24 * time.Hour
+	case constant.SystemMetricsWindowMonth:
+		period = constant.SystemMetricsPeriodHour
+		duration = 30 * 24 * time.Hour
+	default:
+		return nil, errors.New("unsupported window")
+	}
+	return dao.ListSystemMetrics("period = ? and period_time >= ?", period, time.Now().Add(-duration).UnixMilli())
+}
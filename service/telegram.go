@@ -168,6 +168,13 @@ func handleStatus(update tgbotapi.Update) error {
 	text += fmt.Sprintf("Hysteria2 Status: %s\n", status)
 	text += fmt.Sprintf("Number of online users: %d\n", hysteria2MonitorVo.UserTotal)
 	text += fmt.Sprintf("Number of online devices: %d\n", hysteria2MonitorVo.DeviceTotal)
+	for _, item := range hysteria2MonitorVo.Inbounds {
+		status = "Running"
+		if !item.Running {
+			status = "Stop"
+		}
+		text += fmt.Sprintf("Inbound %s: %s, %d users, %d devices\n", item.Name, status, item.UserTotal, item.DeviceTotal)
+	}
 
 	if err := SendWithMessage(update.Message.Chat.ID, text); err != nil {
 		return err
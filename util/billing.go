@@ -0,0 +1,27 @@
+package util
+
+import "h-ui/model/constant"
+
+// BilledTraffic the traffic counted against the quota by the billing mode, an unknown mode counts both directions
+func BilledTraffic(billingMode string, download int64, upload int64) int64 {
+	billedDownload, billedUpload := BilledTrafficSplit(billingMode, download, upload)
+	return billedDownload + billedUpload
+}
+
+// BilledTrafficSplit the download and upload counted against the quota, the direction that is not billed is 0 so the
+// sum a client shows from them, e.g. of the Subscription-Userinfo header, is the billed traffic
+func BilledTrafficSplit(billingMode string, download int64, upload int64) (int64, int64) {
+	switch billingMode {
+	case constant.BillingModeDownload:
+		return download, 0
+	case constant.BillingModeUpload:
+		return 0, upload
+	case constant.BillingModeMax:
+		if download > upload {
+			return download, 0
+		}
+		return 0, upload
+	default:
+		return download, upload
+	}
+}
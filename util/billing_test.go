@@ -0,0 +1,50 @@
+package util
+
+import (
+	"h-ui/model/constant"
+	"testing"
+)
+
+func TestBilledTraffic(t *testing.T) {
+	tests := []struct {
+		mode string
+		want int64
+	}{
+		{constant.BillingModeSum, 30},
+		{constant.BillingModeDownload, 20},
+		{constant.BillingModeUpload, 10},
+		{constant.BillingModeMax, 20},
+		{"", 30},
+	}
+	for _, tt := range tests {
+		if got := BilledTraffic(tt.mode, 20, 10); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestBilledTrafficSplit(t *testing.T) {
+	tests := []struct {
+		mode         string
+		download     int64
+		upload       int64
+		wantDownload int64
+		wantUpload   int64
+	}{
+		{constant.BillingModeSum, 20, 10, 20, 10},
+		{constant.BillingModeDownload, 20, 10, 20, 0},
+		{constant.BillingModeUpload, 20, 10, 0, 10},
+		{constant.BillingModeMax, 20, 10, 20, 0},
+		{constant.BillingModeMax, 10, 20, 0, 20},
+		{"", 20, 10, 20, 10},
+	}
+	for _, tt := range tests {
+		download, upload := BilledTrafficSplit(tt.mode, tt.download, tt.upload)
+		if download != tt.wantDownload || upload != tt.wantUpload {
+			t.Errorf("%s: got %d %d, want %d %d", tt.mode, download, upload, tt.wantDownload, tt.wantUpload)
+		}
+		if download+upload != BilledTraffic(tt.mode, tt.download, tt.upload) {
+			t.Errorf("%s: the split does not sum up to the billed traffic", tt.mode)
+		}
+	}
+}
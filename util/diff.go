@@ -0,0 +1,108 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UnifiedDiff the line diff of two texts in the unified format with 3 lines of context, empty when they are equal
+func UnifiedDiff(fromName string, toName string, from string, to string) string {
+	if from == to {
+		return ""
+	}
+	a := splitLines(from)
+	b := splitLines(to)
+
+	// lcs[i][j] the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type line struct {
+		op   byte
+		text string
+		i, j int // line index in a and b before this line
+	}
+	var lines []line
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		if i < len(a) && j < len(b) && a[i] == b[j] {
+			lines = append(lines, line{' ', a[i], i, j})
+			i++
+			j++
+		} else if i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]) {
+			lines = append(lines, line{'-', a[i], i, j})
+			i++
+		} else {
+			lines = append(lines, line{'+', b[j], i, j})
+			j++
+		}
+	}
+
+	const context = 3
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", fromName, toName))
+	for start := 0; start < len(lines); {
+		if lines[start].op == ' ' {
+			start++
+			continue
+		}
+		// extend the hunk while the next change is within twice the context
+		first := max(start-context, 0)
+		end := start
+		for k := start; k < len(lines); k++ {
+			if lines[k].op != ' ' {
+				end = k
+			} else if k-end > 2*context {
+				break
+			}
+		}
+		last := min(end+context, len(lines)-1)
+
+		fromCount, toCount := 0, 0
+		for _, item := range lines[first : last+1] {
+			if item.op != '+' {
+				fromCount++
+			}
+			if item.op != '-' {
+				toCount++
+			}
+		}
+		builder.WriteString(fmt.Sprintf("@@ -%s +%s @@\n",
+			hunkRange(lines[first].i, fromCount), hunkRange(lines[first].j, toCount)))
+		for _, item := range lines[first : last+1] {
+			builder.WriteByte(item.op)
+			builder.WriteString(item.text)
+			builder.WriteByte('\n')
+		}
+		start = last + 1
+	}
+	return builder.String()
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+func hunkRange(start int, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
@@ -2,9 +2,17 @@ package util
 
 import (
 	"crypto/sha256"
+	"crypto/subtle"
 	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
+var sha224Regexp = regexp.MustCompile("^[0-9a-f]{56}$")
+
+// SHA224String the legacy unsalted hash, only kept to verify passwords that have not been upgraded yet
 func SHA224String(password string) string {
 	hash := sha256.New224()
 	hash.Write([]byte(password))
@@ -15,3 +23,30 @@ func SHA224String(password string) string {
 	}
 	return str
 }
+
+// HashPassword bcrypt in the modular crypt format, the $2a$ prefix records the algorithm
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// VerifyPassword upgrade is true when the password matched a legacy SHA-224 hash and should be rehashed
+func VerifyPassword(hash string, password string) (ok bool, upgrade bool) {
+	if IsBcryptHash(hash) {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil, false
+	}
+	ok = subtle.ConstantTimeCompare([]byte(hash), []byte(SHA224String(password))) == 1
+	return ok, ok
+}
+
+// IsPassHash whether the value is a stored password hash rather than a plain text password
+func IsPassHash(value string) bool {
+	return IsBcryptHash(value) || sha224Regexp.MatchString(value)
+}
+
+func IsBcryptHash(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
@@ -5,3 +5,22 @@ import "testing"
 func TestSHA224String(t *testing.T) {
 	println(SHA224String("sysadmin"))
 }
+
+func TestVerifyPassword(t *testing.T) {
+	hash, err := HashPassword("sysadmin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, upgrade := VerifyPassword(hash, "sysadmin"); !ok || upgrade {
+		t.Errorf("bcrypt: got ok=%v upgrade=%v", ok, upgrade)
+	}
+	if ok, _ := VerifyPassword(hash, "sysadmin1"); ok {
+		t.Errorf("bcrypt: wrong password accepted")
+	}
+	if ok, upgrade := VerifyPassword(SHA224String("sysadmin"), "sysadmin"); !ok || !upgrade {
+		t.Errorf("sha224: got ok=%v upgrade=%v", ok, upgrade)
+	}
+	if ok, upgrade := VerifyPassword(SHA224String("sysadmin"), "sysadmin1"); ok || upgrade {
+		t.Errorf("sha224: got ok=%v upgrade=%v", ok, upgrade)
+	}
+}
@@ -1,11 +1,13 @@
 package util
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
 	"h-ui/model/constant"
+	"io"
 	"os"
 )
 
@@ -38,3 +40,34 @@ func ExportFile(filePath string, data any, t int) error {
 	}
 	return nil
 }
+
+// WriteCsv the first record is the header, e.g. to stream a csv that must not be kept on the disk
+func WriteCsv(w io.Writer, records [][]string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(records); err != nil {
+		logrus.Errorf("WriteCsv write err: %v", err)
+		return errors.New(constant.SysError)
+	}
+	return nil
+}
+
+// ExportCsv the first record is the header, the file is only readable by its owner since it may hold the passwords
+func ExportCsv(filePath string, records [][]string) error {
+	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		logrus.Errorf("ExportCsv create file err filePath: %s err: %v", filePath, err)
+		return errors.New(constant.SysError)
+	}
+	defer file.Close()
+	// an existing file keeps its mode on open
+	if err = file.Chmod(0600); err != nil {
+		logrus.Errorf("ExportCsv chmod file err filePath: %s err: %v", filePath, err)
+		return errors.New(constant.SysError)
+	}
+	writer := csv.NewWriter(file)
+	if err = writer.WriteAll(records); err != nil {
+		logrus.Errorf("ExportCsv write err filePath: %s err: %v", filePath, err)
+		return errors.New(constant.SysError)
+	}
+	return nil
+}
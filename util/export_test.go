@@ -0,0 +1,32 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExportCsvMode(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "accounts.csv")
+	// an existing world readable file is made private too
+	if err := os.WriteFile(filePath, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ExportCsv(filePath, [][]string{{"username", "pass"}, {"user01", "secret"}}); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("got the mode %v, want 0600", info.Mode().Perm())
+	}
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "username,pass\nuser01,secret\n" {
+		t.Errorf("got %q", content)
+	}
+}
@@ -2,56 +2,200 @@ package util
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/google/go-github/v39/github"
+	"github.com/sirupsen/logrus"
+	"h-ui/model/constant"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
 )
 
-var githubClient *github.Client
+// ReleaseSource where the releases are listed and the assets downloaded from
+type ReleaseSource struct {
+	Token     string        // github token, raises the rate limit from 60 to 5000 requests per hour
+	BaseURL   string        // github enterprise url, e.g. https://github.example.com/api/v3/
+	MirrorURL string        // asset url template, {url} {tag} {name} are replaced, e.g. https://mirror.example.com/{url}
+	CacheTTL  time.Duration // how long the cached releases are used without asking upstream
+}
+
+// Release a release with the download urls of its assets by name
+type Release struct {
+	TagName string            `json:"tagName"`
+	Assets  map[string]string `json:"assets"`
+}
+
+type releaseCache struct {
+	FetchedAt int64     `json:"fetchedAt"`
+	Releases  []Release `json:"releases"`
+}
+
+var (
+	githubClient  *github.Client
+	releaseSource = ReleaseSource{CacheTTL: time.Hour}
+	// releaseMutex guards githubClient, releaseSource and the cache file
+	releaseMutex sync.Mutex
+)
 
 func init() {
-	githubClient = github.NewClient(nil)
+	githubClient = github.NewClient(&http.Client{Timeout: 30 * time.Second})
 }
 
-func GetReleaseAssetURL(owner, repo, version, fileName string) (string, error) {
-	ctx := context.Background()
+type tokenTransport struct {
+	token string
+}
 
-	var release *github.RepositoryRelease
-	var err error
-	if version != "" {
-		release, _, err = githubClient.Repositories.GetReleaseByTag(ctx, owner, repo, version)
-		if err != nil {
-			return "", fmt.Errorf("failed to get release for version %s: %v", version, err)
-		}
-	} else {
-		releases, _, err := githubClient.Repositories.ListReleases(ctx, owner, repo, nil)
-		if err != nil {
-			return "", fmt.Errorf("failed to list releases: %v", err)
-		}
-		if len(releases) == 0 {
-			return "", fmt.Errorf("no releases found")
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// SetReleaseSource the cached releases are kept, they do not depend on how they were fetched
+func SetReleaseSource(source ReleaseSource) error {
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	if source.Token != "" {
+		httpClient.Transport = &tokenTransport{token: source.Token}
+	}
+	client := github.NewClient(httpClient)
+	if source.BaseURL != "" {
+		var err error
+		if client, err = github.NewEnterpriseClient(source.BaseURL, source.BaseURL, httpClient); err != nil {
+			return fmt.Errorf("github enterprise url %s is invalid: %v", source.BaseURL, err)
 		}
-		release = releases[0]
 	}
 
-	assets, _, err := githubClient.Repositories.ListReleaseAssets(ctx, owner, repo, release.GetID(), nil)
+	releaseMutex.Lock()
+	defer releaseMutex.Unlock()
+	githubClient = client
+	releaseSource = source
+	return nil
+}
+
+// GetReleaseAssetURLs the download urls of the release assets by name, the latest release when version is empty.
+// The upstreamAssets are never served from the mirror, they are what the other assets are verified with.
+func GetReleaseAssetURLs(owner, repo, version string, upstreamAssets ...string) (map[string]string, error) {
+	releases, err := ListRelease(owner, repo)
 	if err != nil {
-		return "", fmt.Errorf("failed to list release assets: %v", err)
+		return nil, err
+	}
+	if len(releases) == 0 {
+		return nil, fmt.Errorf("no releases found")
 	}
 
-	for _, asset := range assets {
-		if asset.GetName() == fileName {
-			return asset.GetBrowserDownloadURL(), nil
+	release := releases[0]
+	if version != "" {
+		found := false
+		for _, item := range releases {
+			if item.TagName == version {
+				release, found = item, true
+				break
+			}
+		}
+		// older than the listed releases
+		if !found {
+			releaseMutex.Lock()
+			client := githubClient
+			releaseMutex.Unlock()
+			githubRelease, _, err := client.Repositories.GetReleaseByTag(context.Background(), owner, repo, version)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get release for version %s: %v", version, err)
+			}
+			release = toRelease(githubRelease)
 		}
 	}
 
-	return "", fmt.Errorf("file '%s' not found in release '%s'", fileName, release.GetTagName())
+	releaseMutex.Lock()
+	mirrorURL := releaseSource.MirrorURL
+	releaseMutex.Unlock()
+	upstream := map[string]bool{}
+	for _, name := range upstreamAssets {
+		upstream[name] = true
+	}
+	urls := map[string]string{}
+	for name, url := range release.Assets {
+		if mirrorURL != "" && !upstream[name] {
+			url = strings.NewReplacer("{url}", url, "{tag}", release.TagName, "{name}", name).Replace(mirrorURL)
+		}
+		urls[name] = url
+	}
+	return urls, nil
 }
 
-func ListRelease(owner, repo string) ([]*github.RepositoryRelease, error) {
-	ctx := context.Background()
-	releases, _, err := githubClient.Repositories.ListReleases(ctx, owner, repo, nil)
+// ListRelease the releases newest first, served from the cache within the ttl and from the stale cache when upstream
+// is unreachable. The lock is not held while upstream is asked.
+func ListRelease(owner, repo string) ([]Release, error) {
+	key := owner + "/" + repo
+	releaseMutex.Lock()
+	client := githubClient
+	cacheTTL := releaseSource.CacheTTL
+	cache, cached := readReleaseCache()[key]
+	releaseMutex.Unlock()
+	if cached && time.Since(time.UnixMilli(cache.FetchedAt)) < cacheTTL {
+		return cache.Releases, nil
+	}
+
+	githubReleases, _, err := client.Repositories.ListReleases(context.Background(), owner, repo, nil)
 	if err != nil {
+		if cached {
+			logrus.Warnf("failed to list releases, using the releases cached at %s: %v",
+				time.UnixMilli(cache.FetchedAt).Format("2006-01-02 15:04:05"), err)
+			return cache.Releases, nil
+		}
 		return nil, fmt.Errorf("failed to list releases: %v", err)
 	}
+	releases := make([]Release, 0, len(githubReleases))
+	for _, item := range githubReleases {
+		releases = append(releases, toRelease(item))
+	}
+
+	releaseMutex.Lock()
+	defer releaseMutex.Unlock()
+	// read again, the releases of another repo may have been cached meanwhile
+	caches := readReleaseCache()
+	caches[key] = releaseCache{FetchedAt: time.Now().UnixMilli(), Releases: releases}
+	if err = writeReleaseCache(caches); err != nil {
+		logrus.Warnf("failed to write the release cache: %v", err)
+	}
 	return releases, nil
 }
+
+func toRelease(githubRelease *github.RepositoryRelease) Release {
+	release := Release{TagName: githubRelease.GetTagName(), Assets: map[string]string{}}
+	for _, asset := range githubRelease.Assets {
+		release.Assets[asset.GetName()] = asset.GetBrowserDownloadURL()
+	}
+	return release
+}
+
+func readReleaseCache() map[string]releaseCache {
+	caches := map[string]releaseCache{}
+	content, err := os.ReadFile(constant.ReleaseCachePath)
+	if err != nil {
+		return caches
+	}
+	if err = json.Unmarshal(content, &caches); err != nil {
+		logrus.Warnf("the release cache is invalid: %v", err)
+		return map[string]releaseCache{}
+	}
+	return caches
+}
+
+func writeReleaseCache(caches map[string]releaseCache) error {
+	content, err := json.Marshal(caches)
+	if err != nil {
+		return err
+	}
+	tmpPath := constant.ReleaseCachePath + ".tmp"
+	if err = os.WriteFile(tmpPath, content, 0644); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpPath, constant.ReleaseCachePath); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
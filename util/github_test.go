@@ -0,0 +1,144 @@
+package util
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"h-ui/model/constant"
+)
+
+// githubTestServer a github enterprise api listing one release, fail makes the listing fail
+func githubTestServer(t *testing.T, fail *atomic.Bool) (*httptest.Server, *atomic.Int64) {
+	var calls atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/repos/apernet/hysteria/releases" {
+			http.NotFound(w, r)
+			return
+		}
+		calls.Add(1)
+		if fail.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `[{"tag_name":"app/v2.6.1","assets":[`+
+			`{"name":"hysteria-linux-amd64","browser_download_url":"https://github.example.com/hysteria-linux-amd64"},`+
+			`{"name":"hashes.txt","browser_download_url":"https://github.example.com/hashes.txt"}]}]`)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+// releaseTestDir run the test in a temporary directory, the release cache is written relative to it
+func releaseTestDir(t *testing.T) {
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Mkdir(constant.BinDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(dir)
+		_ = SetReleaseSource(ReleaseSource{CacheTTL: time.Hour})
+	})
+}
+
+func TestListReleaseCacheTTL(t *testing.T) {
+	releaseTestDir(t)
+	var fail atomic.Bool
+	server, calls := githubTestServer(t, &fail)
+	if err := SetReleaseSource(ReleaseSource{BaseURL: server.URL + "/", CacheTTL: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		releases, err := ListRelease("apernet", "hysteria")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(releases) != 1 || releases[0].TagName != "app/v2.6.1" {
+			t.Fatalf("got %+v, want app/v2.6.1", releases)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("upstream asked %d times within the ttl, want 1", calls.Load())
+	}
+
+	// expired, upstream is asked again
+	if err := SetReleaseSource(ReleaseSource{BaseURL: server.URL + "/", CacheTTL: time.Nanosecond}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ListRelease("apernet", "hysteria"); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("upstream asked %d times after the ttl, want 2", calls.Load())
+	}
+}
+
+func TestListReleaseStaleCache(t *testing.T) {
+	releaseTestDir(t)
+	var fail atomic.Bool
+	server, calls := githubTestServer(t, &fail)
+	if err := SetReleaseSource(ReleaseSource{BaseURL: server.URL + "/", CacheTTL: time.Nanosecond}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ListRelease("apernet", "hysteria"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(constant.ReleaseCachePath); err != nil {
+		t.Fatalf("the release cache is not written: %v", err)
+	}
+
+	// upstream is down, the expired releases on disk are served
+	fail.Store(true)
+	releases, err := ListRelease("apernet", "hysteria")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(releases) != 1 || releases[0].TagName != "app/v2.6.1" {
+		t.Fatalf("got %+v, want the cached app/v2.6.1", releases)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("upstream asked %d times, want 2", calls.Load())
+	}
+
+	// nothing cached to fall back to
+	if err = os.Remove(constant.ReleaseCachePath); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ListRelease("apernet", "hysteria"); err == nil {
+		t.Fatal("got nil, want the error of upstream")
+	}
+}
+
+func TestGetReleaseAssetURLsMirror(t *testing.T) {
+	releaseTestDir(t)
+	var fail atomic.Bool
+	server, _ := githubTestServer(t, &fail)
+	if err := SetReleaseSource(ReleaseSource{
+		BaseURL:   server.URL + "/",
+		MirrorURL: "https://mirror.example.com/{tag}/{name}",
+		CacheTTL:  time.Hour,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	urls, err := GetReleaseAssetURLs("apernet", "hysteria", "", hysteria2HashesName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if urls["hysteria-linux-amd64"] != "https://mirror.example.com/app/v2.6.1/hysteria-linux-amd64" {
+		t.Fatalf("binary url %s, want the mirror", urls["hysteria-linux-amd64"])
+	}
+	if urls[hysteria2HashesName] != "https://github.example.com/hashes.txt" {
+		t.Fatalf("hashes url %s, want upstream", urls[hysteria2HashesName])
+	}
+}
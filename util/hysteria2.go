@@ -1,14 +1,29 @@
 package util
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"h-ui/model/constant"
 	"io"
 	"net/http"
 	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
 	"runtime"
+	"sort"
+	"strings"
+	"time"
 )
 
+const hysteria2HashesName = "hashes.txt"
+
+var hysteria2VersionRegexp = regexp.MustCompile(`^v\d+(\.\d+)*([-+][0-9A-Za-z.\-]+)?$`)
+
 func GetHysteria2BinPath() string {
 	return constant.BinDir + GetHysteria2BinName()
 }
@@ -21,45 +36,273 @@ func GetHysteria2BinName() string {
 	return hysteria2FileName
 }
 
+func getHysteria2VersionBinPath(version string) string {
+	return constant.Hysteria2VersionDir + version + "/" + GetHysteria2BinName()
+}
+
+// DownloadHysteria2 download the release to a temporary file, verify it against the sha256 published in hashes.txt,
+// install it and make it the active binary, the active binary is untouched until all of that succeeded
 func DownloadHysteria2(version string) error {
 	hysteria2BinName := GetHysteria2BinName()
-	hysteria2BinPath := GetHysteria2BinPath()
 
-	// Download the latest version of Hysteria2
-	url, err := GetReleaseAssetURL("apernet", "hysteria", version, hysteria2BinName)
+	// Download the latest version of Hysteria2, hashes.txt always from github so a mirror cannot replace both
+	urls, err := GetReleaseAssetURLs("apernet", "hysteria", version, hysteria2HashesName)
 	if err != nil {
 		return err
 	}
+	if urls[hysteria2BinName] == "" {
+		return fmt.Errorf("file '%s' not found in the release", hysteria2BinName)
+	}
+	if urls[hysteria2HashesName] == "" {
+		return fmt.Errorf("file '%s' not found in the release, the download cannot be verified", hysteria2HashesName)
+	}
 
-	resp, err := http.Get(url)
+	hashesResp, err := downloadFile(urls[hysteria2HashesName])
+	if err != nil {
+		return err
+	}
+	hashes, err := io.ReadAll(io.LimitReader(hashesResp.Body, 1<<20))
+	_ = hashesResp.Body.Close()
 	if err != nil {
 		return fmt.Errorf("failed to download file: %v", err)
 	}
+	hash, err := parseHysteria2Hash(string(hashes), hysteria2BinName)
+	if err != nil {
+		return err
+	}
+
+	resp, err := downloadFile(urls[hysteria2BinName])
+	if err != nil {
+		return err
+	}
 	defer resp.Body.Close()
 
+	if err = os.MkdirAll(constant.Hysteria2VersionDir, 0755); err != nil {
+		return fmt.Errorf("failed to create dir %s: %v", constant.Hysteria2VersionDir, err)
+	}
+	sha := sha256.New()
+	tmpPath, err := writeExecutable(io.TeeReader(resp.Body, sha), constant.Hysteria2VersionDir)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+	if actual := hex.EncodeToString(sha.Sum(nil)); actual != hash {
+		return fmt.Errorf("sha256 mismatch of %s, expected %s, got %s", hysteria2BinName, hash, actual)
+	}
+
+	installedVersion, err := installHysteria2File(tmpPath)
+	if err != nil {
+		return err
+	}
+	return UseHysteria2Version(installedVersion)
+}
+
+func downloadFile(url string) (*http.Response, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %v", err)
+	}
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to download file, status code: %d", resp.StatusCode)
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("failed to download file, status code: %d", resp.StatusCode)
 	}
+	return resp, nil
+}
 
-	if Exists(hysteria2BinPath) {
-		if err = os.Remove(hysteria2BinPath); err != nil {
-			return fmt.Errorf("failed to remove existing file: %v", err)
+// parseHysteria2Hash the sha256 of the file in hashes.txt, every line is <sha256>  [build/]<file name>
+func parseHysteria2Hash(hashes string, fileName string) (string, error) {
+	for _, line := range strings.Split(hashes, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || path.Base(strings.TrimPrefix(fields[len(fields)-1], "*")) != fileName {
+			continue
+		}
+		hash := strings.ToLower(fields[0])
+		if _, err := hex.DecodeString(hash); err != nil || len(hash) != sha256.Size*2 {
+			return "", fmt.Errorf("the sha256 of %s is invalid", fileName)
 		}
+		return hash, nil
 	}
+	return "", fmt.Errorf("the sha256 of %s not found in %s", fileName, hysteria2HashesName)
+}
 
-	file, err := os.Create(hysteria2BinPath)
+// InstallHysteria2 add the binary to the installed versions, it is verified by running it and the version is detected from the output
+func InstallHysteria2(reader io.Reader) (string, error) {
+	if err := os.MkdirAll(constant.Hysteria2VersionDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create dir %s: %v", constant.Hysteria2VersionDir, err)
+	}
+	tmpPath, err := writeExecutable(reader, constant.Hysteria2VersionDir)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmpPath)
+	return installHysteria2File(tmpPath)
+}
+
+// installHysteria2File move the executable in the versions dir to the dir of its version
+func installHysteria2File(tmpPath string) (string, error) {
+	version, err := GetHysteria2BinVersion(tmpPath)
+	if err != nil {
+		return "", err
+	}
+	versionBinPath := getHysteria2VersionBinPath(version)
+	if err = os.MkdirAll(filepath.Dir(versionBinPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create dir %s: %v", filepath.Dir(versionBinPath), err)
+	}
+	if err = os.Rename(tmpPath, versionBinPath); err != nil {
+		return "", fmt.Errorf("failed to install hysteria2 %s: %v", version, err)
+	}
+	return version, nil
+}
+
+// UseHysteria2Version make an installed version the active binary, it is replaced atomically
+// so a running hysteria2 keeps its binary until it is restarted, the replaced version is kept for RollbackHysteria2Version
+func UseHysteria2Version(version string) error {
+	if !hysteria2VersionRegexp.MatchString(version) {
+		return fmt.Errorf("hysteria2 version %s is invalid", version)
+	}
+	versionBinPath := getHysteria2VersionBinPath(version)
+	if !Exists(versionBinPath) {
+		return fmt.Errorf("hysteria2 %s is not installed", version)
+	}
+	file, err := os.Open(versionBinPath)
 	if err != nil {
-		return fmt.Errorf("failed to create file %s: %v", hysteria2BinPath, err)
+		return fmt.Errorf("failed to open file %s: %v", versionBinPath, err)
 	}
 	defer file.Close()
 
-	_, err = io.Copy(file, resp.Body)
+	previousVersion, err := retainActiveHysteria2()
 	if err != nil {
-		return fmt.Errorf("failed to write to file: %v", err)
+		return err
 	}
 
-	if err = os.Chmod(hysteria2BinPath, 0755); err != nil {
-		return fmt.Errorf("failed to change file permissions: %v", err)
+	tmpPath, err := writeExecutable(file, constant.BinDir)
+	if err != nil {
+		return err
+	}
+	if err = os.Rename(tmpPath, GetHysteria2BinPath()); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace %s: %v", GetHysteria2BinPath(), err)
+	}
+	if previousVersion != "" && previousVersion != version {
+		if err = os.WriteFile(constant.Hysteria2PreviousVersionPath, []byte(previousVersion), 0644); err != nil {
+			return fmt.Errorf("failed to write file %s: %v", constant.Hysteria2PreviousVersionPath, err)
+		}
 	}
 	return nil
 }
+
+// retainActiveHysteria2 make sure the active binary is one of the installed versions, e.g. one downloaded before
+// the versions were kept, an active binary that does not run is not worth keeping
+func retainActiveHysteria2() (string, error) {
+	if !Exists(GetHysteria2BinPath()) {
+		return "", nil
+	}
+	version, err := GetHysteria2BinVersion(GetHysteria2BinPath())
+	if err != nil {
+		return "", nil
+	}
+	if Exists(getHysteria2VersionBinPath(version)) {
+		return version, nil
+	}
+	file, err := os.Open(GetHysteria2BinPath())
+	if err != nil {
+		return "", fmt.Errorf("failed to open file %s: %v", GetHysteria2BinPath(), err)
+	}
+	defer file.Close()
+	return InstallHysteria2(file)
+}
+
+// GetPreviousHysteria2Version the version that was active before the last switch, empty when there is none
+func GetPreviousHysteria2Version() string {
+	previousVersion, err := os.ReadFile(constant.Hysteria2PreviousVersionPath)
+	if err != nil {
+		return ""
+	}
+	version := strings.TrimSpace(string(previousVersion))
+	if !Exists(getHysteria2VersionBinPath(version)) {
+		return ""
+	}
+	return version
+}
+
+// RollbackHysteria2Version switch back to the version that was active before the last switch
+func RollbackHysteria2Version() (string, error) {
+	previousVersion := GetPreviousHysteria2Version()
+	if previousVersion == "" {
+		return "", errors.New("no previous hysteria2 version to roll back to")
+	}
+	return previousVersion, UseHysteria2Version(previousVersion)
+}
+
+// ListHysteria2Version the installed versions, newest first
+func ListHysteria2Version() ([]string, error) {
+	entries, err := os.ReadDir(constant.Hysteria2VersionDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("failed to read dir %s: %v", constant.Hysteria2VersionDir, err)
+	}
+	versions := []string{}
+	for _, item := range entries {
+		if item.IsDir() && hysteria2VersionRegexp.MatchString(item.Name()) && Exists(getHysteria2VersionBinPath(item.Name())) {
+			versions = append(versions, item.Name())
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return CompareVersion(strings.TrimPrefix(versions[i], "v"), strings.TrimPrefix(versions[j], "v")) > 0
+	})
+	return versions, nil
+}
+
+// GetHysteria2BinVersion run the binary with version and detect its version
+func GetHysteria2BinVersion(binPath string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, binPath, "version").CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("not a runnable hysteria2 binary: %v", err)
+	}
+	return parseHysteria2Version(string(output))
+}
+
+// parseHysteria2Version the version line of hysteria version, e.g. Version:	v2.5.1
+func parseHysteria2Version(output string) (string, error) {
+	for _, line := range strings.Split(output, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found || strings.TrimSpace(key) != "Version" {
+			continue
+		}
+		version := strings.TrimSpace(value)
+		if !strings.HasPrefix(version, "v") {
+			version = "v" + version
+		}
+		if !hysteria2VersionRegexp.MatchString(version) {
+			return "", fmt.Errorf("hysteria2 version %s is invalid", version)
+		}
+		return version, nil
+	}
+	return "", errors.New("not a hysteria2 binary, the version is missing from its output")
+}
+
+// writeExecutable write the reader to a temporary executable in dir, named after the bin so it also runs on windows
+func writeExecutable(reader io.Reader, dir string) (string, error) {
+	file, err := os.CreateTemp(dir, "*-"+GetHysteria2BinName())
+	if err != nil {
+		return "", fmt.Errorf("failed to create file in %s: %v", dir, err)
+	}
+	if _, err = io.Copy(file, reader); err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write to file: %v", err)
+	}
+	if err = file.Close(); err != nil {
+		_ = os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write to file: %v", err)
+	}
+	if err = os.Chmod(file.Name(), 0755); err != nil {
+		_ = os.Remove(file.Name())
+		return "", fmt.Errorf("failed to change file permissions: %v", err)
+	}
+	return file.Name(), nil
+}
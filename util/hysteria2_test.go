@@ -0,0 +1,48 @@
+package util
+
+import "testing"
+
+func TestParseHysteria2Version(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    string
+		wantErr bool
+	}{
+		{"release", "Version:\tv2.5.1\nBuildDate:\t2024-08-11T12:00:00Z\nBuildType:\trelease\n", "v2.5.1", false},
+		{"without v", "Version: 2.6.0\n", "v2.6.0", false},
+		{"pre-release", "Version:\tv2.6.0-rc1\n", "v2.6.0-rc1", false},
+		{"path", "Version:\t../../etc\n", "", true},
+		{"missing", "Usage: hysteria [command]\n", "", true},
+	}
+	for _, tt := range tests {
+		got, err := parseHysteria2Version(tt.output)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("%s: got %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseHysteria2Hash(t *testing.T) {
+	hash := "8d4e5c2a0f1b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5"
+	hashes := "0000000000000000000000000000000000000000000000000000000000000000  build/hysteria-linux-386\n" +
+		hash + "  build/hysteria-linux-amd64\n"
+	tests := []struct {
+		name     string
+		hashes   string
+		fileName string
+		want     string
+		wantErr  bool
+	}{
+		{"build prefix", hashes, "hysteria-linux-amd64", hash, false},
+		{"binary mode", hash + " *hysteria-linux-amd64", "hysteria-linux-amd64", hash, false},
+		{"prefix of another name", hashes, "hysteria-linux", "", true},
+		{"invalid hash", "xyz  hysteria-linux-amd64", "hysteria-linux-amd64", "", true},
+	}
+	for _, tt := range tests {
+		got, err := parseHysteria2Hash(tt.hashes, tt.fileName)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("%s: got %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package util
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// GetSocketCounts the tcp and udp sockets in use over ipv4 and ipv6, linux only
+func GetSocketCounts() (int64, int64, error) {
+	counts := map[string]int64{}
+	for _, name := range []string{"/proc/net/sockstat", "/proc/net/sockstat6"} {
+		file, err := os.Open(name)
+		if err != nil {
+			if name == "/proc/net/sockstat6" {
+				// ipv6 is disabled
+				continue
+			}
+			return 0, 0, err
+		}
+		for protocol, inuse := range parseSockstat(file) {
+			counts[protocol] += inuse
+		}
+		_ = file.Close()
+	}
+	return counts["TCP"] + counts["TCP6"], counts["UDP"] + counts["UDP6"], nil
+}
+
+// parseSockstat the sockets in use by protocol, a line is like "TCP: inuse 4 orphan 0 tw 1 alloc 4 mem 0"
+func parseSockstat(reader io.Reader) map[string]int64 {
+	counts := map[string]int64{}
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || fields[1] != "inuse" {
+			continue
+		}
+		if value, err := strconv.ParseInt(fields[2], 10, 64); err == nil {
+			counts[strings.TrimSuffix(fields[0], ":")] = value
+		}
+	}
+	return counts
+}
+
+// GetUdpRcvbufErrors the udp datagrams dropped since boot because a receive buffer was full, over ipv4 and ipv6, linux only
+func GetUdpRcvbufErrors() (uint64, error) {
+	file, err := os.Open("/proc/net/snmp")
+	if err != nil {
+		return 0, err
+	}
+	drops := parseSnmpUdpRcvbufErrors(file)
+	_ = file.Close()
+	if file, err = os.Open("/proc/net/snmp6"); err == nil {
+		drops += parseSnmp6UdpRcvbufErrors(file)
+		_ = file.Close()
+	}
+	return drops, nil
+}
+
+// parseSnmpUdpRcvbufErrors the RcvbufErrors of /proc/net/snmp, the "Udp:" header line names the columns of the "Udp:" line after it
+func parseSnmpUdpRcvbufErrors(reader io.Reader) uint64 {
+	var header []string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] != "Udp:" {
+			continue
+		}
+		if header == nil {
+			header = fields
+			continue
+		}
+		for i := 1; i < len(header) && i < len(fields); i++ {
+			if header[i] == "RcvbufErrors" {
+				value, _ := strconv.ParseUint(fields[i], 10, 64)
+				return value
+			}
+		}
+		return 0
+	}
+	return 0
+}
+
+// parseSnmp6UdpRcvbufErrors the Udp6RcvbufErrors of /proc/net/snmp6, a line is a name and a value
+func parseSnmp6UdpRcvbufErrors(reader io.Reader) uint64 {
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "Udp6RcvbufErrors" {
+			value, _ := strconv.ParseUint(fields[1], 10, 64)
+			return value
+		}
+	}
+	return 0
+}
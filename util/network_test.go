@@ -0,0 +1,35 @@
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSockstat(t *testing.T) {
+	content := "sockets: used 18\n" +
+		"TCP: inuse 4 orphan 0 tw 1 alloc 4 mem 0\n" +
+		"UDP: inuse 2 mem 0\n" +
+		"FRAG: inuse 0 memory 0\n"
+	counts := parseSockstat(strings.NewReader(content))
+	if counts["TCP"] != 4 || counts["UDP"] != 2 || len(counts) != 3 {
+		t.Errorf("got %v", counts)
+	}
+}
+
+func TestParseUdpRcvbufErrors(t *testing.T) {
+	snmp := "Tcp: RtoAlgorithm RtoMin\n" +
+		"Tcp: 1 200\n" +
+		"Udp: InDatagrams NoPorts InErrors OutDatagrams RcvbufErrors SndbufErrors\n" +
+		"Udp: 36 0 12 36 7 0\n" +
+		"UdpLite: InDatagrams NoPorts InErrors OutDatagrams RcvbufErrors SndbufErrors\n" +
+		"UdpLite: 0 0 0 0 9 0\n"
+	if got := parseSnmpUdpRcvbufErrors(strings.NewReader(snmp)); got != 7 {
+		t.Errorf("snmp: got %d, want 7", got)
+	}
+	snmp6 := "Udp6InErrors                    \t4\n" +
+		"Udp6RcvbufErrors                \t3\n" +
+		"UdpLite6RcvbufErrors            \t1\n"
+	if got := parseSnmp6UdpRcvbufErrors(strings.NewReader(snmp6)); got != 3 {
+		t.Errorf("snmp6: got %d, want 3", got)
+	}
+}
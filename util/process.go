@@ -0,0 +1,86 @@
+package util
+
+import (
+	"bufio"
+	"fmt"
+	"h-ui/model/bo"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/process"
+)
+
+// GetProcessMetrics the cpu percent is measured since the last call with the same process, the metrics
+// the platform does not provide are left 0
+func GetProcessMetrics(p *process.Process) (bo.ProcessMetrics, error) {
+	now := time.Now()
+	cpuPercent, err := p.Percent(0)
+	if err != nil {
+		return bo.ProcessMetrics{}, fmt.Errorf("failed to get the cpu percent of process %d: %v", p.Pid, err)
+	}
+	memoryInfo, err := p.MemoryInfo()
+	if err != nil {
+		return bo.ProcessMetrics{}, fmt.Errorf("failed to get the memory of process %d: %v", p.Pid, err)
+	}
+	metrics := bo.ProcessMetrics{
+		Time:       now.UnixMilli(),
+		CpuPercent: cpuPercent,
+		Rss:        memoryInfo.RSS,
+	}
+	metrics.Fds, _ = p.NumFDs()
+	metrics.Threads, _ = p.NumThreads()
+	if createTime, err := p.CreateTime(); err == nil {
+		metrics.Uptime = (now.UnixMilli() - createTime) / 1000
+	}
+	metrics.UdpDrops, _ = getUdpDrops(p.Pid)
+	return metrics, nil
+}
+
+// getUdpDrops sum the drops of the udp sockets the process holds, linux only
+func getUdpDrops(pid int32) (uint64, error) {
+	fdDir := fmt.Sprintf("/proc/%d/fd", pid)
+	entries, err := os.ReadDir(fdDir)
+	if err != nil {
+		return 0, err
+	}
+	inodes := map[string]bool{}
+	for _, item := range entries {
+		link, err := os.Readlink(filepath.Join(fdDir, item.Name()))
+		if err == nil && strings.HasPrefix(link, "socket:[") && strings.HasSuffix(link, "]") {
+			inodes[link[len("socket:["):len(link)-1]] = true
+		}
+	}
+
+	var drops uint64
+	for _, name := range []string{"udp", "udp6"} {
+		file, err := os.Open(fmt.Sprintf("/proc/%d/net/%s", pid, name))
+		if err != nil {
+			continue
+		}
+		drops += parseUdpDrops(file, inodes)
+		_ = file.Close()
+	}
+	return drops, nil
+}
+
+// parseUdpDrops the drops of the sockets with the inodes in /proc/net/udp, the inode is the 10th column and the drops the 13th
+func parseUdpDrops(reader io.Reader, inodes map[string]bool) uint64 {
+	var drops uint64
+	scanner := bufio.NewScanner(reader)
+	// the header
+	scanner.Scan()
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 13 || !inodes[fields[9]] {
+			continue
+		}
+		if value, err := strconv.ParseUint(fields[12], 10, 64); err == nil {
+			drops += value
+		}
+	}
+	return drops
+}
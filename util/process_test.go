@@ -0,0 +1,26 @@
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseUdpDrops(t *testing.T) {
+	content := "   sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops\n" +
+		"  312: 00000000:01BB 00000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 41127 2 0000000000000000 17\n" +
+		"  513: 3500007F:0035 00000000:0000 07 00000000:00000000 00:00000000 00000000   101        0 18291 2 0000000000000000 5\n" +
+		"  600: 00000000:2F2D 00000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 41130 2 0000000000000000 3\n"
+	tests := []struct {
+		name   string
+		inodes map[string]bool
+		want   uint64
+	}{
+		{"own sockets", map[string]bool{"41127": true, "41130": true}, 20},
+		{"other sockets", map[string]bool{"99999": true}, 0},
+	}
+	for _, tt := range tests {
+		if got := parseUdpDrops(strings.NewReader(content), tt.inodes); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package util
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type PromLabel struct {
+	Name  string
+	Value string
+}
+
+type PromSample struct {
+	Labels []PromLabel
+	Value  float64
+}
+
+// PromMetric a metric family in the prometheus text format, Type is counter or gauge
+type PromMetric struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []PromSample
+}
+
+var (
+	promHelpReplacer  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	promLabelReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+// WritePrometheus write the metrics in the prometheus text exposition format 0.0.4, a metric without samples is skipped
+func WritePrometheus(w io.Writer, metrics []PromMetric) error {
+	writer := bufio.NewWriter(w)
+	for _, metric := range metrics {
+		if len(metric.Samples) == 0 {
+			continue
+		}
+		writer.WriteString("# HELP " + metric.Name + " " + promHelpReplacer.Replace(metric.Help) + "\n")
+		writer.WriteString("# TYPE " + metric.Name + " " + metric.Type + "\n")
+		for _, sample := range metric.Samples {
+			writer.WriteString(metric.Name)
+			if len(sample.Labels) > 0 {
+				writer.WriteString("{")
+				for i, label := range sample.Labels {
+					if i > 0 {
+						writer.WriteString(",")
+					}
+					writer.WriteString(label.Name + `="` + promLabelReplacer.Replace(label.Value) + `"`)
+				}
+				writer.WriteString("}")
+			}
+			writer.WriteString(" " + formatPromValue(sample.Value) + "\n")
+		}
+	}
+	return writer.Flush()
+}
+
+func formatPromValue(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,36 @@
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	metrics := []PromMetric{
+		{
+			Name: "hui_account_upload_bytes_total",
+			Help: "Upload of the account\nin bytes",
+			Type: "counter",
+			Samples: []PromSample{
+				{Labels: []PromLabel{{"username", `a"b\c`}}, Value: 1024},
+				{Labels: []PromLabel{{"username", "d"}, {"plan", "e"}}, Value: 1.5e12},
+			},
+		},
+		{Name: "hui_empty", Help: "skipped", Type: "gauge"},
+		{Name: "hui_up", Help: "Up", Type: "gauge", Samples: []PromSample{{Value: 1}}},
+	}
+	var builder strings.Builder
+	if err := WritePrometheus(&builder, metrics); err != nil {
+		t.Fatal(err)
+	}
+	want := "# HELP hui_account_upload_bytes_total Upload of the account\\nin bytes\n" +
+		"# TYPE hui_account_upload_bytes_total counter\n" +
+		"hui_account_upload_bytes_total{username=\"a\\\"b\\\\c\"} 1024\n" +
+		"hui_account_upload_bytes_total{username=\"d\",plan=\"e\"} 1.5e+12\n" +
+		"# HELP hui_up Up\n" +
+		"# TYPE hui_up gauge\n" +
+		"hui_up 1\n"
+	if got := builder.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,43 @@
+package util
+
+import (
+	"h-ui/model/constant"
+	"time"
+)
+
+// NextResetTime the first reset of the cycle after last, the zero time if the cycle never resets
+func NextResetTime(cycle string, interval int64, anchor time.Time, last time.Time) time.Time {
+	switch cycle {
+	case constant.ResetCycleMonthly:
+		next := monthDay(last.Year(), last.Month(), anchor)
+		if !next.After(last) {
+			next = monthDay(last.Year(), last.Month()+1, anchor)
+		}
+		return next
+	case constant.ResetCycleDays:
+		if interval <= 0 {
+			return time.Time{}
+		}
+		if last.Before(anchor) {
+			return anchor
+		}
+		n := int(last.Sub(anchor).Hours()/24) / int(interval)
+		next := anchor.AddDate(0, 0, n*int(interval))
+		for !next.After(last) {
+			next = next.AddDate(0, 0, int(interval))
+		}
+		return next
+	default:
+		return time.Time{}
+	}
+}
+
+// monthDay the anchor's day and clock in the month, clamped to the last day of a shorter month
+func monthDay(year int, month time.Month, anchor time.Time) time.Time {
+	lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, anchor.Location()).Day()
+	day := anchor.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(year, month, day, anchor.Hour(), anchor.Minute(), anchor.Second(), 0, anchor.Location())
+}
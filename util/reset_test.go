@@ -0,0 +1,33 @@
+package util
+
+import (
+	"h-ui/model/constant"
+	"testing"
+	"time"
+)
+
+func TestNextResetTime(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name     string
+		cycle    string
+		interval int64
+		anchor   time.Time
+		last     time.Time
+		want     time.Time
+	}{
+		{"monthly same month", constant.ResetCycleMonthly, 0, date(2024, 1, 15), date(2024, 3, 2), date(2024, 3, 15)},
+		{"monthly next month", constant.ResetCycleMonthly, 0, date(2024, 1, 15), date(2024, 3, 15), date(2024, 4, 15)},
+		{"monthly short month", constant.ResetCycleMonthly, 0, date(2024, 1, 31), date(2024, 2, 1), date(2024, 2, 29)},
+		{"days", constant.ResetCycleDays, 10, date(2024, 1, 1), date(2024, 1, 1), date(2024, 1, 11)},
+		{"days late", constant.ResetCycleDays, 10, date(2024, 1, 1), date(2024, 1, 25), date(2024, 1, 31)},
+		{"never", constant.ResetCycleNever, 0, date(2024, 1, 1), date(2024, 1, 25), time.Time{}},
+	}
+	for _, tt := range tests {
+		if got := NextResetTime(tt.cycle, tt.interval, tt.anchor, tt.last); !got.Equal(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
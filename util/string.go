@@ -1,6 +1,19 @@
 package util
 
-import "strings"
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 字符串必须6-32位是字母或者数字或部分特殊字符的组合
+var validStrRegexp = regexp.MustCompile("^[a-zA-Z0-9!@#$%^&*()_+-=]{6,32}$")
+
+func IsValidStr(str string) bool {
+	return validStrRegexp.MatchString(str)
+}
 
 func CompareVersion(version1, version2 string) int {
 	v1 := strings.Split(version1, ".")
@@ -34,3 +47,21 @@ func CompareVersion(version1, version2 string) int {
 	// The version number is exactly the same
 	return 0
 }
+
+// ParseThresholds the comma separated positive numbers in descending order
+func ParseThresholds(str string) ([]int64, error) {
+	var thresholds []int64
+	for _, item := range strings.Split(str, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		threshold, err := strconv.ParseInt(item, 10, 64)
+		if err != nil || threshold <= 0 {
+			return nil, fmt.Errorf("threshold %s is invalid", item)
+		}
+		thresholds = append(thresholds, threshold)
+	}
+	sort.Slice(thresholds, func(i, j int) bool { return thresholds[i] > thresholds[j] })
+	return thresholds, nil
+}
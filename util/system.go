@@ -0,0 +1,57 @@
+package util
+
+import (
+	"fmt"
+	"h-ui/model/bo"
+	"strconv"
+	"time"
+
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/shirou/gopsutil/load"
+	psnet "github.com/shirou/gopsutil/net"
+)
+
+// GetSystemMetrics the cpu percent is measured since the last call so it does not block, the load average
+// and the socket metrics are left 0 on the platforms without them
+func GetSystemMetrics() (bo.SystemMetrics, error) {
+	metrics := bo.SystemMetrics{Time: time.Now().UnixMilli()}
+	cpuPercent, err := cpu.Percent(0, false)
+	if err != nil || len(cpuPercent) == 0 {
+		return bo.SystemMetrics{}, fmt.Errorf("failed to get the cpu percent: %v", err)
+	}
+	if metrics.CpuPercent, err = strconv.ParseFloat(fmt.Sprintf("%.1f", cpuPercent[0]), 64); err != nil {
+		return bo.SystemMetrics{}, err
+	}
+	if metrics.MemPercent, err = GetMemPercent(); err != nil {
+		return bo.SystemMetrics{}, fmt.Errorf("failed to get the memory percent: %v", err)
+	}
+	if metrics.DiskPercent, err = GetDiskPercent(); err != nil {
+		return bo.SystemMetrics{}, fmt.Errorf("failed to get the disk percent: %v", err)
+	}
+	if avg, err := load.Avg(); err == nil {
+		metrics.Load1 = avg.Load1
+		metrics.Load5 = avg.Load5
+		metrics.Load15 = avg.Load15
+	}
+	counters, err := psnet.IOCounters(true)
+	if err != nil {
+		return bo.SystemMetrics{}, fmt.Errorf("failed to get the network counters: %v", err)
+	}
+	metrics.Interfaces = make(map[string]bo.InterfaceCounters, len(counters))
+	for _, item := range counters {
+		metrics.Interfaces[item.Name] = bo.InterfaceCounters{
+			BytesRecv:   item.BytesRecv,
+			BytesSent:   item.BytesSent,
+			PacketsRecv: item.PacketsRecv,
+			PacketsSent: item.PacketsSent,
+		}
+		if item.Name == "lo" {
+			continue
+		}
+		metrics.NetRecv += item.BytesRecv
+		metrics.NetSent += item.BytesSent
+	}
+	metrics.TcpSockets, metrics.UdpSockets, _ = GetSocketCounts()
+	metrics.UdpRcvbufErrors, _ = GetUdpRcvbufErrors()
+	return metrics, nil
+}